@@ -0,0 +1,26 @@
+/*
+Command docker-credential-go-credentials is a docker credential helper that stores registry credentials with
+go-credentials, under ~/.docker_credentials by default (see package dockercredential).
+
+Usage:
+
+	docker-credential-go-credentials [--app NAME] [--format ini|json] get|store|erase|list
+
+To use it, put it on the PATH and set credsStore in ~/.docker/config.json:
+
+	{
+		"credsStore": "go-credentials"
+	}
+
+As docker cannot pass arguments to a helper, the application can also be set with the DOCKER_CREDENTIALS_APP
+environment variable.
+*/
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout))
+}
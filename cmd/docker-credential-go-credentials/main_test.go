@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/dockercredential"
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestHelper(t *testing.T) {
+	assert, log, _, homeDirectory, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing the docker credential helper as docker runs it.")
+	os.Setenv(global.LOG_LEVEL_ENVIRONMENT_KEY, "disabled")
+	var stdout bytes.Buffer
+
+	exitCode := run([]string{"store"}, strings.NewReader(`{"ServerURL": "registry.example.com", "Username": "user", "Secret": "secret"}`), &stdout)
+	assert.Equal(exitOk, exitCode, stdout.String())
+
+	exitCode = run([]string{"get"}, strings.NewReader("registry.example.com"), &stdout)
+	assert.Equal(exitOk, exitCode)
+	assert.JSONEq(`{"ServerURL": "registry.example.com", "Username": "user", "Secret": "secret"}`, stdout.String())
+
+	os.Setenv(applicationEnvironmentKey, global.TEST_VAR_APPLICATION_NAME)
+	defer os.Unsetenv(applicationEnvironmentKey)
+	stdout.Reset()
+	exitCode = run([]string{"get"}, strings.NewReader("registry.example.com"), &stdout)
+	assert.Equal(exitError, exitCode)
+	assert.Equal(dockercredential.ERR_CREDENTIALS_NOT_FOUND+"\n", stdout.String())
+
+	_, statErr := os.Stat(homeDirectory + "/.docker_credentials/credentials")
+	assert.NoError(statErr)
+}
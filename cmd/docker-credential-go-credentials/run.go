@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/engi-fyi/go-credentials/dockercredential"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+const defaultApplicationName = "docker_credentials"
+const applicationEnvironmentKey = "DOCKER_CREDENTIALS_APP"
+
+const exitOk = 0
+const exitError = 1
+
+/*
+run parses args, carries out the operation they name with the request read from stdin and returns the exit code: 0 on
+success and 1 otherwise. As docker expects of a helper, errors are written to stdout rather than stderr.
+*/
+func run(args []string, stdin io.Reader, stdout io.Writer) int {
+	app := defaultApplicationName
+
+	if value, ok := os.LookupEnv(applicationEnvironmentKey); ok && value != "" {
+		app = value
+	}
+
+	flags := flag.NewFlagSet("docker-credential-go-credentials", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	flags.StringVar(&app, "app", app, "the name of the application to store the credentials with")
+	format := flags.String("format", "", "the output type to store credentials as (ini or json)")
+
+	if parseErr := flags.Parse(args); parseErr != nil {
+		return exitError
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitError
+	}
+
+	sourceFactory, factoryErr := serializer.NewDetectedFactory(app, *format)
+
+	if factoryErr != nil {
+		fmt.Fprintln(stdout, factoryErr)
+		return exitError
+	}
+
+	if runErr := dockercredential.Run(sourceFactory, flags.Arg(0), stdin, stdout); runErr != nil {
+		fmt.Fprintln(stdout, runErr)
+		return exitError
+	}
+
+	return exitOk
+}
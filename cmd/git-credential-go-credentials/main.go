@@ -0,0 +1,25 @@
+/*
+Command git-credential-go-credentials is a git credential helper that stores git HTTPS credentials with go-credentials,
+under ~/.git_credentials by default (see package gitcredential).
+
+Usage:
+
+	git-credential-go-credentials [--app NAME] [--format ini|json] get|store|erase
+
+To use it, put it on the PATH and configure git with:
+
+	git config --global credential.helper go-credentials
+
+or, to store the credentials with another application's:
+
+	git config --global credential.helper "go-credentials --app NAME"
+*/
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestHelper(t *testing.T) {
+	assert, log, _, _, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing the git credential helper as git runs it.")
+	os.Setenv(global.LOG_LEVEL_ENVIRONMENT_KEY, "disabled")
+	request := "protocol=https\nhost=example.com\n"
+	var stdout, stderr bytes.Buffer
+
+	exitCode := run([]string{"store"}, strings.NewReader(request+"username=git_user\npassword=git_token\n"), &stdout, &stderr)
+	assert.Equal(exitOk, exitCode, stderr.String())
+
+	exitCode = run([]string{"--app", global.TEST_VAR_APPLICATION_NAME, "get"}, strings.NewReader(request), &stdout, &stderr)
+	assert.Equal(exitOk, exitCode, stderr.String())
+	assert.Equal("", stdout.String())
+
+	exitCode = run([]string{"get"}, strings.NewReader(request), &stdout, &stderr)
+	assert.Equal(exitOk, exitCode, stderr.String())
+	assert.Equal(request+"username=git_user\npassword=git_token\n", stdout.String())
+
+	exitCode = run([]string{"get"}, strings.NewReader("protocol=https\n"), &stdout, &stderr)
+	assert.Equal(exitError, exitCode)
+
+	exitCode = run([]string{}, strings.NewReader(request), &stdout, &stderr)
+	assert.Equal(exitUsage, exitCode)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/engi-fyi/go-credentials/gitcredential"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+const defaultApplicationName = "git_credentials"
+
+const exitOk = 0
+const exitError = 1
+const exitUsage = 2
+
+/*
+run parses args, carries out the operation they name with the request read from stdin and returns the exit code: 0 on
+success, 1 if the operation failed and 2 if the arguments were not valid.
+*/
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("git-credential-go-credentials", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	app := flags.String("app", defaultApplicationName, "the name of the application to store the credentials with")
+	format := flags.String("format", "", "the output type to store credentials as (ini or json)")
+
+	if parseErr := flags.Parse(args); parseErr != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	sourceFactory, factoryErr := serializer.NewDetectedFactory(*app, *format)
+
+	if factoryErr != nil {
+		fmt.Fprintln(stderr, factoryErr)
+		return exitError
+	}
+
+	if runErr := gitcredential.Run(sourceFactory, flags.Arg(0), stdin, stdout); runErr != nil {
+		fmt.Fprintln(stderr, runErr)
+		return exitError
+	}
+
+	return exitOk
+}
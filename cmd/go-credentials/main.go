@@ -0,0 +1,32 @@
+/*
+Command go-credentials inspects and edits the credentials stored by an application that uses the go-credentials
+library, under ~/.application_name.
+
+Usage:
+
+	go-credentials --app NAME [--profile NAME] [--section NAME] [--format ini|json|env|aws] COMMAND [ARGS]
+
+Commands:
+
+	get KEY                    print the value of an attribute, or of username or password
+	set KEY VALUE [KEY VALUE]  set attributes, creating the profile if username and password are both given
+	delete [KEY]               delete an attribute, or the whole profile if no KEY is given
+	list-profiles              print the name of every stored profile
+	show [--show-secrets]      print the profile, with the username and password redacted by default
+	export [dotenv|posix|fish] print the profile as a dotenv file or shell script (dotenv by default)
+	import FILE                save the profile from a dotenv file written by export
+	exec [--] CMD [ARGS]       run CMD with the profile in its environment, exiting with its exit code
+
+A VALUE of - is read from the first line of standard input, which keeps passwords out of the process list. If --format
+is not given, the format of the existing credentials file is used, or ini if there is none. Flags for exec must be given
+before it, as everything after exec is passed to CMD.
+*/
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
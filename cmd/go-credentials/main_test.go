@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func runTest(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	exitCode := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return exitCode, stdout.String(), stderr.String()
+}
+
+func TestCommands(t *testing.T) {
+	assert, log, _, homeDirectory, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing the go-credentials commands.")
+	os.Setenv(global.LOG_LEVEL_ENVIRONMENT_KEY, "disabled")
+	app := "--app=" + global.TEST_VAR_APPLICATION_NAME
+
+	exitCode, _, stderr := runTest("", "get", "username")
+	assert.Equal(exitUsage, exitCode)
+	assert.Contains(stderr, errAppRequired)
+
+	exitCode, _, _ = runTest("", app, "unknown")
+	assert.Equal(exitUsage, exitCode)
+
+	exitCode, _, stderr = runTest("", app, "get", global.USERNAME_LABEL)
+	assert.Equal(exitError, exitCode)
+	assert.Contains(stderr, errProfileNotFound)
+
+	exitCode, _, stderr = runTest("", app, "set", global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE)
+	assert.Equal(exitError, exitCode)
+	assert.Contains(stderr, errNewProfile)
+
+	exitCode, _, stderr = runTest(global.TEST_VAR_PASSWORD+"\n", app, "set", global.USERNAME_LABEL, global.TEST_VAR_USERNAME, global.PASSWORD_LABEL, "-")
+	assert.Equal(exitOk, exitCode, stderr)
+
+	exitCode, _, stderr = runTest("", app, "set", global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE, "--section", global.TEST_VAR_FIRST_SECTION_KEY)
+	assert.Equal(exitOk, exitCode, stderr)
+
+	exitCode, stdout, _ := runTest("", app, "get", global.PASSWORD_LABEL)
+	assert.Equal(exitOk, exitCode)
+	assert.Equal(global.TEST_VAR_PASSWORD+"\n", stdout)
+
+	exitCode, stdout, _ = runTest("", app, "--section", global.TEST_VAR_FIRST_SECTION_KEY, "get", global.TEST_VAR_ATTRIBUTE_NAME_LABEL)
+	assert.Equal(exitOk, exitCode)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE+"\n", stdout)
+
+	exitCode, stdout, _ = runTest("", app, "show")
+	assert.Equal(exitOk, exitCode)
+	assert.Contains(stdout, global.PASSWORD_LABEL+" = "+global.REDACTED_VALUE)
+	assert.NotContains(stdout, global.TEST_VAR_PASSWORD)
+	assert.Contains(stdout, "["+global.TEST_VAR_FIRST_SECTION_KEY+"]\n"+global.TEST_VAR_ATTRIBUTE_NAME_LABEL+" = "+global.TEST_VAR_ATTRIBUTE_VALUE)
+
+	exitCode, stdout, _ = runTest("", app, "show", "--show-secrets")
+	assert.Equal(exitOk, exitCode)
+	assert.Contains(stdout, global.PASSWORD_LABEL+" = "+global.TEST_VAR_PASSWORD)
+
+	exitCode, stdout, _ = runTest("", app, "export")
+	assert.Equal(exitOk, exitCode)
+	dotEnvFile := filepath.Join(homeDirectory, "credentials.env")
+	assert.NoError(ioutil.WriteFile(dotEnvFile, []byte(stdout), 0600))
+
+	exitCode, _, stderr = runTest("", app, "delete")
+	assert.Equal(exitOk, exitCode, stderr)
+
+	exitCode, stdout, _ = runTest("", app, "list-profiles")
+	assert.Equal(exitOk, exitCode)
+	assert.Equal("", stdout)
+
+	exitCode, _, stderr = runTest("", app, "import", dotEnvFile)
+	assert.Equal(exitOk, exitCode, stderr)
+
+	exitCode, stdout, _ = runTest("", app, "--section", global.TEST_VAR_FIRST_SECTION_KEY, "get", global.TEST_VAR_ATTRIBUTE_NAME_LABEL)
+	assert.Equal(exitOk, exitCode)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE+"\n", stdout)
+
+	exitCode, _, stderr = runTest("", app, "--profile", global.TEST_VAR_FIRST_PROFILE_LABEL, "set", global.USERNAME_LABEL, global.TEST_VAR_USERNAME, global.PASSWORD_LABEL, global.TEST_VAR_PASSWORD)
+	assert.Equal(exitOk, exitCode, stderr)
+
+	exitCode, stdout, _ = runTest("", app, "list-profiles")
+	assert.Equal(exitOk, exitCode)
+	assert.Equal(global.DEFAULT_PROFILE_NAME+"\n"+global.TEST_VAR_FIRST_PROFILE_LABEL+"\n", stdout)
+
+	exitCode, _, stderr = runTest("", app, "--section", global.TEST_VAR_FIRST_SECTION_KEY, "delete", global.TEST_VAR_ATTRIBUTE_NAME_LABEL)
+	assert.Equal(exitOk, exitCode, stderr)
+
+	exitCode, _, stderr = runTest("", app, "--section", global.TEST_VAR_FIRST_SECTION_KEY, "get", global.TEST_VAR_ATTRIBUTE_NAME_LABEL)
+	assert.Equal(exitError, exitCode)
+	assert.Contains(stderr, errAttributeNotFound)
+
+	exitCode, _, stderr = runTest("", app, "--profile", global.TEST_VAR_FIRST_PROFILE_LABEL, "delete")
+	assert.Equal(exitOk, exitCode, stderr)
+
+	exitCode, stdout, _ = runTest("", app, "list-profiles")
+	assert.Equal(exitOk, exitCode)
+	assert.Equal(global.DEFAULT_PROFILE_NAME+"\n", stdout)
+}
+
+func TestExec(t *testing.T) {
+	assert, log, _, homeDirectory, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing the exec command.")
+
+	if runtime.GOOS == "windows" {
+		t.Skip("exec is tested with sh")
+	}
+
+	os.Setenv(global.LOG_LEVEL_ENVIRONMENT_KEY, "disabled")
+	app := "--app=" + global.TEST_VAR_APPLICATION_NAME
+	environmentFile := filepath.Join(homeDirectory, "environment")
+
+	exitCode, _, stderr := runTest("", app, "exec", "sh", "-c", "exit 0")
+	assert.Equal(exitError, exitCode)
+	assert.Contains(stderr, errProfileNotFound)
+
+	exitCode, _, stderr = runTest("", app, "set", global.USERNAME_LABEL, global.TEST_VAR_USERNAME, global.PASSWORD_LABEL, global.TEST_VAR_PASSWORD)
+	assert.Equal(exitOk, exitCode, stderr)
+
+	exitCode, _, stderr = runTest("", app, "exec")
+	assert.Equal(exitError, exitCode)
+	assert.Contains(stderr, errExecArguments)
+
+	// The child shares standard output with the current process, so it is sent to a file to check its environment.
+	environmentOutput, createErr := os.Create(environmentFile)
+	assert.NoError(createErr)
+	originalStdout := os.Stdout
+	os.Stdout = environmentOutput
+	exitCode, _, stderr = runTest("", app, "exec", "--", "env")
+	os.Stdout = originalStdout
+	assert.NoError(environmentOutput.Close())
+	assert.Equal(exitOk, exitCode, stderr)
+	environment, readErr := ioutil.ReadFile(environmentFile)
+	assert.NoError(readErr)
+	assert.Contains(string(environment), "="+global.TEST_VAR_USERNAME+"\n")
+	assert.Contains(string(environment), "="+global.TEST_VAR_PASSWORD+"\n")
+
+	// Flags after exec belong to the child process.
+	exitCode, _, stderr = runTest("", app, "exec", "sh", "-c", "exit 3", "--profile", "ignored")
+	assert.Equal(3, exitCode, stderr)
+
+	exitCode, _, stderr = runTest("", app, "exec", "sh", "-c", "kill -TERM $$")
+	assert.Equal(128+int(syscall.SIGTERM), exitCode, stderr)
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/credential"
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+const exitOk = 0
+const exitError = 1
+const exitUsage = 2
+
+const errAppRequired = "--app is required"
+const errProfileNotFound = "the profile does not exist"
+const errAttributeNotFound = "the attribute does not exist"
+const errSetArguments = "set takes pairs of KEY VALUE"
+const errNewProfile = "the profile does not exist, set both username and password to create it"
+const errExecArguments = "exec takes the command to run"
+
+// options holds the flags shared by every command.
+type options struct {
+	app         string
+	profile     string
+	section     string
+	format      string
+	showSecrets bool
+	stdin       *bufio.Reader
+	stdout      io.Writer
+}
+
+type command func(sourceFactory *factory.Factory, commandOptions options, args []string) error
+
+// exitStatus is returned by a command to make run exit with a particular code, such as the exit code of a child process.
+type exitStatus int
+
+func (thisStatus exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(thisStatus))
+}
+
+var commands = map[string]command{
+	"get":           runGet,
+	"set":           runSet,
+	"delete":        runDelete,
+	"list-profiles": runListProfiles,
+	"show":          runShow,
+	"export":        runExport,
+	"import":        runImport,
+	"exec":          runExec,
+}
+
+/*
+run parses args, runs the command they name and returns the exit code: 0 on success, 1 if the command failed and 2 if
+the arguments were not valid. Flags can be given before, after or between the arguments of the command, except for
+exec, where everything after exec belongs to the child process and its exit code is returned.
+*/
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	commandOptions := options{stdin: bufio.NewReader(stdin), stdout: stdout}
+	flags := flag.NewFlagSet("go-credentials", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&commandOptions.app, "app", "", "the name of the application the credentials belong to")
+	flags.StringVar(&commandOptions.profile, "profile", global.DEFAULT_PROFILE_NAME, "the profile to use")
+	flags.StringVar(&commandOptions.section, "section", "", "the section of the profile to get, set or delete attributes in")
+	flags.StringVar(&commandOptions.format, "format", "", "the output type to store credentials as (ini, json, env or aws)")
+	flags.BoolVar(&commandOptions.showSecrets, "show-secrets", false, "show the username and password in show")
+
+	if parseErr := flags.Parse(args); parseErr != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	commandName := flags.Arg(0)
+	myCommand, exists := commands[commandName]
+
+	if !exists {
+		fmt.Fprintf(stderr, "unknown command %q\n", commandName)
+		flags.Usage()
+		return exitUsage
+	}
+
+	var commandArgs []string
+
+	if commandName == "exec" {
+		commandArgs = flags.Args()[1:]
+
+		if len(commandArgs) > 0 && commandArgs[0] == "--" {
+			commandArgs = commandArgs[1:]
+		}
+	} else {
+		var parseErr error
+		commandArgs, parseErr = parseInterleaved(flags, flags.Args()[1:])
+
+		if parseErr != nil {
+			return exitUsage
+		}
+	}
+
+	if commandOptions.app == "" {
+		fmt.Fprintln(stderr, errAppRequired)
+		return exitUsage
+	}
+
+	sourceFactory, factoryErr := serializer.NewDetectedFactory(commandOptions.app, commandOptions.format)
+
+	if factoryErr != nil {
+		fmt.Fprintln(stderr, factoryErr)
+		return exitError
+	}
+
+	if commandErr := myCommand(sourceFactory, commandOptions, commandArgs); commandErr != nil {
+		if status, isStatus := commandErr.(exitStatus); isStatus {
+			return int(status)
+		}
+
+		fmt.Fprintln(stderr, commandErr)
+		return exitError
+	}
+
+	return exitOk
+}
+
+// parseInterleaved parses flags that are mixed in with the arguments of a command, returning the arguments in order.
+func parseInterleaved(flags *flag.FlagSet, args []string) ([]string, error) {
+	var commandArgs []string
+
+	for {
+		if parseErr := flags.Parse(args); parseErr != nil {
+			return nil, parseErr
+		}
+
+		if flags.NArg() == 0 {
+			return commandArgs, nil
+		}
+
+		commandArgs = append(commandArgs, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// loadProfile loads the profile named by the options, returning errProfileNotFound if it is not stored.
+func loadProfile(sourceFactory *factory.Factory, commandOptions options) (*credential.Credential, error) {
+	profiles, listErr := serializer.ListProfiles(sourceFactory)
+
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	for _, profileName := range profiles {
+		if profileName == commandOptions.profile {
+			return credential.LoadFromProfile(commandOptions.profile, sourceFactory)
+		}
+	}
+
+	return nil, errors.New(errProfileNotFound)
+}
+
+// inSection returns the Credential to get, set or delete attributes on, taking --section into account.
+func inSection(myCredential *credential.Credential, commandOptions options) *credential.Credential {
+	if commandOptions.section == "" {
+		return myCredential
+	}
+
+	return myCredential.Section(commandOptions.section)
+}
+
+func runGet(sourceFactory *factory.Factory, commandOptions options, args []string) error {
+	if len(args) != 1 {
+		return errors.New("get takes a KEY")
+	}
+
+	myCredential, loadErr := loadProfile(sourceFactory, commandOptions)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	value := inSection(myCredential, commandOptions).GetAttribute(args[0])
+
+	if value == "" {
+		return errors.New(errAttributeNotFound)
+	}
+
+	_, printErr := fmt.Fprintln(commandOptions.stdout, value)
+	return printErr
+}
+
+func runSet(sourceFactory *factory.Factory, commandOptions options, args []string) error {
+	if len(args) == 0 || len(args)%2 != 0 {
+		return errors.New(errSetArguments)
+	}
+
+	values := make(map[string]string)
+	var keys []string
+
+	for i := 0; i < len(args); i += 2 {
+		value := args[i+1]
+
+		if value == "-" {
+			line, readErr := commandOptions.stdin.ReadString('\n')
+
+			if readErr != nil && readErr != io.EOF {
+				return readErr
+			}
+
+			value = strings.TrimRight(line, "\r\n")
+		}
+
+		keys = append(keys, args[i])
+		values[args[i]] = value
+	}
+
+	myCredential, loadErr := loadProfile(sourceFactory, commandOptions)
+
+	if loadErr != nil && loadErr.Error() != errProfileNotFound {
+		return loadErr
+	}
+
+	if myCredential == nil {
+		username, password := values[global.USERNAME_LABEL], values[global.PASSWORD_LABEL]
+
+		if commandOptions.section != "" || username == "" || password == "" {
+			return errors.New(errNewProfile)
+		}
+
+		var newErr error
+		myCredential, newErr = credential.NewProfile(commandOptions.profile, sourceFactory, username, password)
+
+		if newErr != nil {
+			return newErr
+		}
+	}
+
+	for _, key := range keys {
+		if setErr := inSection(myCredential, commandOptions).SetAttribute(key, values[key]); setErr != nil {
+			return setErr
+		}
+	}
+
+	return myCredential.Save()
+}
+
+func runDelete(sourceFactory *factory.Factory, commandOptions options, args []string) error {
+	if len(args) > 1 {
+		return errors.New("delete takes an optional KEY")
+	}
+
+	myCredential, loadErr := loadProfile(sourceFactory, commandOptions)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if len(args) == 0 {
+		return credential.DeleteProfile(commandOptions.profile, sourceFactory)
+	}
+
+	if deleteErr := inSection(myCredential, commandOptions).DeleteAttribute(args[0]); deleteErr != nil {
+		return deleteErr
+	}
+
+	return myCredential.Save()
+}
+
+func runListProfiles(sourceFactory *factory.Factory, commandOptions options, args []string) error {
+	profiles, listErr := serializer.ListProfiles(sourceFactory)
+
+	if listErr != nil {
+		return listErr
+	}
+
+	for _, profileName := range profiles {
+		if _, printErr := fmt.Fprintln(commandOptions.stdout, profileName); printErr != nil {
+			return printErr
+		}
+	}
+
+	return nil
+}
+
+func runShow(sourceFactory *factory.Factory, commandOptions options, args []string) error {
+	myCredential, loadErr := loadProfile(sourceFactory, commandOptions)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	username, password, attributes := myCredential.Serialize()
+
+	if !commandOptions.showSecrets {
+		username, password = global.REDACTED_VALUE, global.REDACTED_VALUE
+	}
+
+	var builder strings.Builder
+	builder.WriteString("[credential]\n")
+	builder.WriteString(global.USERNAME_LABEL + " = " + username + "\n")
+	builder.WriteString(global.PASSWORD_LABEL + " = " + password + "\n")
+	sectionNames := make([]string, 0, len(attributes))
+
+	for sectionName := range attributes {
+		if len(attributes[sectionName]) > 0 {
+			sectionNames = append(sectionNames, sectionName)
+		}
+	}
+
+	sort.Strings(sectionNames)
+
+	for _, sectionName := range sectionNames {
+		builder.WriteString("\n[" + sectionName + "]\n")
+		keys := make([]string, 0, len(attributes[sectionName]))
+
+		for key := range attributes[sectionName] {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			builder.WriteString(key + " = " + attributes[sectionName][key] + "\n")
+		}
+	}
+
+	_, printErr := io.WriteString(commandOptions.stdout, builder.String())
+	return printErr
+}
+
+func runExport(sourceFactory *factory.Factory, commandOptions options, args []string) error {
+	if len(args) > 1 {
+		return errors.New("export takes an optional export type (dotenv, posix or fish)")
+	}
+
+	exportType := global.EXPORT_TYPE_DOTENV
+
+	if len(args) == 1 {
+		exportType = args[0]
+	}
+
+	myCredential, loadErr := loadProfile(sourceFactory, commandOptions)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	exported, exportErr := myCredential.Export(exportType)
+
+	if exportErr != nil {
+		return exportErr
+	}
+
+	_, printErr := io.WriteString(commandOptions.stdout, exported)
+	return printErr
+}
+
+func runImport(sourceFactory *factory.Factory, commandOptions options, args []string) error {
+	if len(args) != 1 {
+		return errors.New("import takes a FILE")
+	}
+
+	importedCredential, importErr := credential.LoadFromDotEnv(commandOptions.profile, sourceFactory, args[0])
+
+	if importErr != nil {
+		return importErr
+	}
+
+	return importedCredential.Save()
+}
+
+// runExec runs a command with the profile injected into its environment, and exits with the command's exit code.
+func runExec(sourceFactory *factory.Factory, commandOptions options, args []string) error {
+	if len(args) == 0 {
+		return errors.New(errExecArguments)
+	}
+
+	myCredential, loadErr := loadProfile(sourceFactory, commandOptions)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	exitCode, execErr := myCredential.Exec(context.Background(), args[0], args[1:]...)
+
+	if execErr != nil {
+		return execErr
+	}
+
+	if exitCode != exitOk {
+		return exitStatus(exitCode)
+	}
+
+	return nil
+}
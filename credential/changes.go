@@ -0,0 +1,72 @@
+package credential
+
+import (
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/profile"
+)
+
+/*
+IsDirty returns true if the Credential has anything to save: it has never been loaded or saved, its username or password
+has changed, it would now be saved to a different profile or output type, or an attribute of its Profile has changed.
+*/
+func (thisCredential *Credential) IsDirty() bool {
+	return thisCredential.isCredentialDirty() || thisCredential.Profile.IsDirty()
+}
+
+/*
+GetChanges lists everything that has changed since the Credential was last loaded or saved. Changes to the username and
+password come first, with a blank Section and a Key of global.USERNAME_LABEL or global.PASSWORD_LABEL, followed by the
+changes to the Profile's attributes (see profile.GetChanges). The values are not redacted, so take care if the changes
+are logged or displayed.
+*/
+func (thisCredential *Credential) GetChanges() []profile.AttributeChange {
+	changes := make([]profile.AttributeChange, 0)
+	var savedUsername, savedPassword string
+	changeType := global.CHANGE_TYPE_ADDED
+
+	if thisCredential.saved != nil {
+		savedUsername = thisCredential.saved.username
+		savedPassword = thisCredential.saved.password
+		changeType = global.CHANGE_TYPE_MODIFIED
+	}
+
+	if thisCredential.saved == nil || thisCredential.Username != savedUsername {
+		changes = append(changes, profile.AttributeChange{Section: "", Key: global.USERNAME_LABEL, Type: changeType, OldValue: savedUsername, NewValue: thisCredential.Username})
+	}
+
+	if thisCredential.saved == nil || thisCredential.Password != savedPassword {
+		changes = append(changes, profile.AttributeChange{Section: "", Key: global.PASSWORD_LABEL, Type: changeType, OldValue: savedPassword, NewValue: thisCredential.Password})
+	}
+
+	return append(changes, thisCredential.Profile.GetChanges()...)
+}
+
+// isCredentialDirty returns true if the credentials file (or username and password variables) need to be written.
+func (thisCredential *Credential) isCredentialDirty() bool {
+	if thisCredential.saved == nil {
+		return true
+	}
+
+	currentState := thisCredential.currentState()
+	currentState.fileHashes = thisCredential.saved.fileHashes
+	return *thisCredential.saved != currentState
+}
+
+// markClean records the Credential and its Profile as matching what has been loaded or saved.
+func (thisCredential *Credential) markClean() {
+	currentState := thisCredential.currentState()
+	currentState.fileHashes = thisCredential.hashFiles()
+	thisCredential.saved = &currentState
+	thisCredential.Profile.MarkClean()
+}
+
+func (thisCredential *Credential) currentState() savedCredential {
+	return savedCredential{
+		outputType:    thisCredential.Factory.OutputType,
+		profileName:   thisCredential.Profile.Name,
+		usernameLabel: thisCredential.Factory.GetAlternateUsername(),
+		passwordLabel: thisCredential.Factory.GetAlternatePassword(),
+		username:      thisCredential.Username,
+		password:      thisCredential.Password,
+	}
+}
@@ -0,0 +1,158 @@
+package credential
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/profile"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+/*
+MergeFromDisk performs a three-way merge between the Credential as it was loaded, the Credential as it is now and what
+is currently saved on disk (see profile.MergeAttributes). Changes made only on disk are applied to the Credential and
+local changes are kept. Where both sides changed the same value, the local value is kept and a conflict is returned;
+changes to the username and password are reported with a blank Section, as with GetChanges.
+
+Once merged, the Credential is treated as if it was loaded from what is now on disk, so a following Save will write the
+merged result rather than fail with ERR_SAVE_CONFLICT.
+*/
+func (thisCredential *Credential) MergeFromDisk() ([]profile.AttributeConflict, error) {
+	if !thisCredential.Factory.Initialized || !thisCredential.Initialized {
+		return nil, errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	mySerializer := serializer.New(thisCredential.Factory, thisCredential.Profile.Name)
+	diskUsername, diskPassword, diskAttributes, loadErr := mySerializer.Deserialize()
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	baseCredential := map[string]map[string]string{"": {}}
+
+	if thisCredential.saved != nil {
+		baseCredential[""][global.USERNAME_LABEL] = thisCredential.saved.username
+		baseCredential[""][global.PASSWORD_LABEL] = thisCredential.saved.password
+	}
+
+	mergedCredential, conflicts := profile.MergeAttributes(
+		baseCredential,
+		map[string]map[string]string{"": {global.USERNAME_LABEL: thisCredential.Username, global.PASSWORD_LABEL: thisCredential.Password}},
+		map[string]map[string]string{"": {global.USERNAME_LABEL: diskUsername, global.PASSWORD_LABEL: diskPassword}},
+	)
+	mergedAttributes, attributeConflicts := profile.MergeAttributes(
+		thisCredential.Profile.GetSavedAttributes(),
+		thisCredential.Profile.GetAllAttributes(),
+		diskAttributes,
+	)
+
+	replaceErr := replaceAttributes(thisCredential.Profile, diskAttributes)
+
+	if replaceErr != nil {
+		return nil, replaceErr
+	}
+
+	thisCredential.Profile.MarkClean()
+	replaceErr = replaceAttributes(thisCredential.Profile, mergedAttributes)
+
+	if replaceErr != nil {
+		return nil, replaceErr
+	}
+
+	thisCredential.Username = mergedCredential[""][global.USERNAME_LABEL]
+	thisCredential.Password = mergedCredential[""][global.PASSWORD_LABEL]
+	thisCredential.Factory.Log.Debug().Int("conflicts", len(conflicts)+len(attributeConflicts)).Msg("Merged credential with changes on disk.")
+
+	diskState := thisCredential.currentState()
+	diskState.username = diskUsername
+	diskState.password = diskPassword
+	diskState.fileHashes = thisCredential.hashFiles()
+	thisCredential.saved = &diskState
+
+	return append(conflicts, attributeConflicts...), nil
+}
+
+/*
+checkForConflict returns ERR_SAVE_CONFLICT if what is stored for the Credential's Profile has changed since the
+Credential was loaded or last saved. The files are hashed first, so they are only read in full when something has
+changed, and only the Credential's own values are compared, so saving another profile into the shared credentials file,
+or editing comments, is not a conflict. The env output type lives in memory, so it is never checked.
+*/
+func (thisCredential *Credential) checkForConflict() error {
+	saved := thisCredential.saved
+
+	if saved == nil || saved.outputType == global.OUTPUT_TYPE_ENV ||
+		saved.outputType != thisCredential.Factory.OutputType || saved.profileName != thisCredential.Profile.Name {
+		return nil
+	}
+
+	if thisCredential.hashFiles() == saved.fileHashes {
+		return nil
+	}
+
+	mySerializer := serializer.New(thisCredential.Factory, thisCredential.Profile.Name)
+	diskUsername, diskPassword, diskAttributes, loadErr := mySerializer.Deserialize()
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if diskUsername != saved.username || diskPassword != saved.password ||
+		len(profile.DiffAttributes(thisCredential.Profile.GetSavedAttributes(), diskAttributes)) > 0 {
+		thisCredential.Factory.Log.Error().Str("profile", thisCredential.Profile.Name).Msg(ERR_SAVE_CONFLICT)
+		return errors.New(ERR_SAVE_CONFLICT)
+	}
+
+	return nil
+}
+
+// hashFiles returns a hash of the files the Credential's Profile is stored in (see serializer.GetStorageFiles), as they are on disk.
+func (thisCredential *Credential) hashFiles() string {
+	fileHash := sha256.New()
+	fileNames, _ := serializer.New(thisCredential.Factory, thisCredential.Profile.Name).GetStorageFiles()
+
+	for _, fileName := range fileNames {
+		//#nosec
+		contents, readErr := ioutil.ReadFile(fileName)
+
+		if readErr != nil {
+			contents = nil
+		}
+
+		contentsHash := sha256.Sum256(contents)
+		fileHash.Write(contentsHash[:])
+	}
+
+	return hex.EncodeToString(fileHash.Sum(nil))
+}
+
+// replaceAttributes makes the attributes of targetProfile match attributes exactly.
+func replaceAttributes(targetProfile *profile.Profile, attributes map[string]map[string]string) error {
+	for sectionName, values := range targetProfile.GetAllAttributes() {
+		for key := range values {
+			if _, exists := attributes[sectionName][key]; !exists {
+				deleteErr := targetProfile.DeleteAttribute(sectionName, key)
+
+				if deleteErr != nil {
+					return deleteErr
+				}
+			}
+		}
+	}
+
+	for sectionName, values := range attributes {
+		for key, value := range values {
+			setErr := targetProfile.SetAttribute(sectionName, key, value)
+
+			if setErr != nil {
+				return setErr
+			}
+		}
+	}
+
+	return nil
+}
@@ -1,14 +1,28 @@
 package credential
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"github.com/engi-fyi/go-credentials/factory"
 	"github.com/engi-fyi/go-credentials/global"
 	"github.com/engi-fyi/go-credentials/profile"
 	"github.com/engi-fyi/go-credentials/serializer"
 	"github.com/rs/zerolog"
 	as "github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestCredentialNewBadFactory(t *testing.T) {
@@ -195,6 +209,33 @@ func TestCredentialLoadFromFileNoFile(t *testing.T) {
 	}
 }
 
+func TestCredentialLoadDetectsFileType(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing loading a credential saved as json with a factory set to ini.")
+	soErr := testFactory.SetOutputType(global.OUTPUT_TYPE_JSON)
+	assert.NoError(soErr)
+	testCredential, tcErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(tcErr)
+	setErr := testCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE)
+	assert.NoError(setErr)
+	saveErr := testCredential.Save()
+	assert.NoError(saveErr)
+
+	soErr = testFactory.SetOutputType(global.OUTPUT_TYPE_INI)
+	assert.NoError(soErr)
+	loadedCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	assert.Equal(global.TEST_VAR_USERNAME, loadedCredential.Username)
+	assert.Equal(global.TEST_VAR_PASSWORD, loadedCredential.Password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, loadedCredential.GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+
+	setErr = loadedCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED)
+	assert.NoError(setErr)
+	saveErr = loadedCredential.Save()
+	assert.EqualError(saveErr, serializer.ERR_FILE_TYPE_MISMATCH)
+	parentDirectoryCleanup(t)
+}
+
 func TestCredentialLoadFactoryInvalidOutputType(t *testing.T) {
 	assert, log := global.InitTest(t)
 	log.Info().Msg("Testing an invalid output type against a factory object.")
@@ -247,9 +288,16 @@ func TestCredentialSaveEnv(t *testing.T) {
 	assert.NoError(deployErr)
 
 	for _, label := range []string{global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL, global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL, global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL} {
-		_, exists := os.LookupEnv(label)
+		_, exists := testCredentials.Factory.GetEnvironment()[label]
 		assert.True(exists)
+		_, exists = os.LookupEnv(label)
+		assert.False(exists)
 	}
+
+	loadedCredential, credErr := Load(testCredentials.Factory)
+	assert.NoError(credErr)
+	assert.Equal(global.TEST_VAR_USERNAME, loadedCredential.Username)
+	assert.Equal(global.TEST_VAR_PASSWORD, loadedCredential.Password)
 }
 
 func TestCredentialLoadEnv(t *testing.T) {
@@ -258,17 +306,46 @@ func TestCredentialLoadEnv(t *testing.T) {
 
 	soErr := testFactory.SetOutputType(global.OUTPUT_TYPE_ENV)
 	assert.NoError(soErr)
+	testFactory.SetEnvironment(map[string]string{
+		global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL:       global.TEST_VAR_USERNAME,
+		global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL:       global.TEST_VAR_PASSWORD,
+		global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL: global.TEST_VAR_ATTRIBUTE_VALUE,
+	})
 	testCredential, credErr := Load(testFactory)
 	assert.NoError(credErr)
 
 	assert.Equal(global.TEST_VAR_USERNAME, testCredential.Username)
 	assert.Equal(global.TEST_VAR_PASSWORD, testCredential.Password)
 	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, testCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+}
 
-	for _, label := range []string{global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL, global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL, global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL} {
-		usErr := os.Unsetenv(label)
-		assert.NoError(usErr)
+func TestCredentialClearEnv(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing clearing credentials from the environment.")
+	soErr := testFactory.SetOutputType(global.OUTPUT_TYPE_ENV)
+	assert.NoError(soErr)
+	testFactory.UseEnvironment = true
+	testCredential, tcErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(tcErr)
+	saveErr := testCredential.Save()
+	assert.NoError(saveErr)
+	_, exists := os.LookupEnv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
+	assert.True(exists)
+
+	clearErr := testCredential.ClearEnv()
+	assert.NoError(clearErr)
+
+	for _, label := range []string{global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL, global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL} {
+		_, exists = os.LookupEnv(label)
+		assert.False(exists)
 	}
+
+	_, credErr := Load(testFactory)
+	assert.EqualError(credErr, serializer.ERR_REQUIRED_VARIABLE_USERNAME_NOT_FOUND)
+	assert.Equal(global.TEST_VAR_USERNAME, testCredential.Username)
+
+	clearErr = (&Credential{Factory: testFactory}).ClearEnv()
+	assert.EqualError(clearErr, ERR_NOT_INITIALIZED)
 }
 
 func TestCredentialCreateNoProfile(t *testing.T) {
@@ -427,6 +504,18 @@ func TestSectionBlank(t *testing.T) {
 	assert.Equal(global.SECTION_NAME_BLANK, sectionCredential.selectedSection)
 }
 
+func TestSectionReserved(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing to ensure attributes cannot be set in the reserved format section")
+	testFactory, _ := factory.New(global.TEST_VAR_APPLICATION_NAME)
+	testCredential, tcErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(tcErr)
+
+	setErr := testCredential.Section(global.FORMAT_SECTION_NAME).SetAttribute(global.FORMAT_VERSION_LABEL, global.FORMAT_VERSION)
+	assert.EqualError(setErr, profile.ERR_SECTION_NAME_RESERVED)
+	assert.Equal("", testCredential.Section(global.FORMAT_SECTION_NAME).GetAttribute(global.FORMAT_VERSION_LABEL))
+}
+
 func TestProfile(t *testing.T) {
 	assert, _ := global.InitTest(t)
 
@@ -447,6 +536,142 @@ func TestProfile(t *testing.T) {
 	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attrValue)
 }
 
+func TestCredentialExport(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing exporting a credential for use by another process.")
+	testCredential, tcErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(tcErr)
+	setErr := testCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE)
+	assert.NoError(setErr)
+
+	exported, exportErr := testCredential.Export(global.EXPORT_TYPE_DOTENV)
+	assert.NoError(exportErr)
+	assert.Contains(exported, global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL+"=\"a_test_username\"\n")
+	assert.Contains(exported, global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL+"=\"a global attribute value\"\n")
+
+	_, exportErr = testCredential.Export(global.OUTPUT_TYPE_INVALID)
+	assert.EqualError(exportErr, serializer.ERR_UNRECOGNIZED_EXPORT_TYPE)
+
+	exportFile := testFactory.ParentDirectory + "default.sh"
+	exportErr = testCredential.ExportToFile(global.EXPORT_TYPE_POSIX, exportFile)
+	assert.NoError(exportErr)
+	assert.FileExists(exportFile)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialLoadFromDotEnv(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing loading a credential from a dotenv file.")
+	testCredential, tcErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(tcErr)
+	setErr := testCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE)
+	assert.NoError(setErr)
+	dotEnvFile := testFactory.ParentDirectory + ".env"
+	exportErr := testCredential.ExportToFile(global.EXPORT_TYPE_DOTENV, dotEnvFile)
+	assert.NoError(exportErr)
+
+	loadedCredential, loadErr := LoadFromDotEnv(global.DEFAULT_PROFILE_NAME, testFactory, dotEnvFile)
+	assert.NoError(loadErr)
+	assert.Equal(global.TEST_VAR_USERNAME, loadedCredential.Username)
+	assert.Equal(global.TEST_VAR_PASSWORD, loadedCredential.Password)
+	assert.Equal(global.DEFAULT_PROFILE_NAME, loadedCredential.Profile.Name)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, loadedCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+
+	_, loadErr = LoadFromDotEnv(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory, dotEnvFile)
+	assert.EqualError(loadErr, serializer.ERR_REQUIRED_VARIABLE_USERNAME_NOT_FOUND)
+
+	_, loadErr = LoadFromDotEnv(global.DEFAULT_PROFILE_NAME, &factory.Factory{}, dotEnvFile)
+	assert.EqualError(loadErr, ERR_FACTORY_MUST_BE_INITIALIZED)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialCommand(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing building a command with the credential in its environment.")
+	testCredential, tcErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(tcErr)
+	setErr := testCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE)
+	assert.NoError(setErr)
+
+	command, commandErr := testCredential.Command(context.Background(), "env")
+	assert.NoError(commandErr)
+	assert.Contains(command.Env, global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL+"="+global.TEST_VAR_USERNAME)
+	assert.Contains(command.Env, global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL+"="+global.TEST_VAR_PASSWORD)
+	assert.Contains(command.Env, global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL+"="+global.TEST_VAR_ATTRIBUTE_VALUE)
+
+	if _, lookErr := exec.LookPath("env"); lookErr == nil {
+		output, runErr := command.Output()
+		assert.NoError(runErr)
+		assert.Contains(string(output), global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL+"="+global.TEST_VAR_PASSWORD+"\n")
+	}
+
+	for _, label := range []string{global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL, global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL, global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL} {
+		_, exists := os.LookupEnv(label)
+		assert.False(exists)
+	}
+
+	_, commandErr = (&Credential{}).Command(context.Background(), "env")
+	assert.EqualError(commandErr, ERR_NOT_INITIALIZED)
+}
+
+func TestCredentialExec(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing running a child process with the credential in its environment.")
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the exec tests rely on sh")
+	}
+
+	testCredential, tcErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(tcErr)
+
+	exitCode, execErr := testCredential.Exec(context.Background(), "sh", "-c", "exit 3")
+	assert.NoError(execErr)
+	assert.Equal(3, exitCode)
+
+	exitCode, execErr = testCredential.Exec(context.Background(), "true")
+	assert.NoError(execErr)
+	assert.Equal(0, exitCode)
+
+	_, execErr = testCredential.Exec(context.Background(), global.TEST_VAR_BAD_PROFILE_LABEL)
+	assert.Error(execErr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, execErr = testCredential.Exec(ctx, "sleep", "5")
+	assert.Equal(context.DeadlineExceeded, execErr)
+}
+
+func TestCredentialExecForwardsSignals(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing signals are forwarded to a child process.")
+
+	if runtime.GOOS == "windows" {
+		t.Skip("signals cannot be forwarded on windows")
+	}
+
+	testCredential, tcErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(tcErr)
+	startedFile := testFactory.ParentDirectory + "started"
+
+	go func() {
+		for i := 0; i < 100; i++ {
+			if _, statErr := os.Stat(startedFile); statErr == nil {
+				self, _ := os.FindProcess(os.Getpid())
+				_ = self.Signal(syscall.SIGTERM)
+				return
+			}
+
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+
+	exitCode, execErr := testCredential.Exec(context.Background(), "sh", "-c", "touch "+startedFile+"; exec sleep 5")
+	assert.NoError(execErr)
+	assert.Equal(128+int(syscall.SIGTERM), exitCode)
+	parentDirectoryCleanup(t)
+}
+
 func buildTestCredentials() (*Credential, error) {
 	buildFactory, factoryErr := factory.New(global.TEST_VAR_APPLICATION_NAME)
 
@@ -457,6 +682,701 @@ func buildTestCredentials() (*Credential, error) {
 	return New(buildFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
 }
 
+func TestCredentialDirtyTracking(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing a credential only writes what has changed when saved.")
+	testCredential, newErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.True(testCredential.IsDirty())
+	assert.Len(testCredential.GetChanges(), 2)
+
+	setErr := testCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE)
+	assert.NoError(setErr)
+	assert.NoError(testCredential.Save())
+	assert.False(testCredential.IsDirty())
+	assert.Empty(testCredential.GetChanges())
+
+	// Nothing has changed, so neither file should be written again.
+	savedTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(os.Chtimes(testFactory.CredentialFile, savedTime, savedTime))
+	assert.NoError(os.Chtimes(testCredential.Profile.ConfigFileLocation, savedTime, savedTime))
+	assert.NoError(testCredential.Save())
+	assertModTime(t, testFactory.CredentialFile, savedTime, true)
+	assertModTime(t, testCredential.Profile.ConfigFileLocation, savedTime, true)
+
+	// Only an attribute has changed, so only the config file should be written.
+	setErr = testCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED)
+	assert.NoError(setErr)
+	assert.True(testCredential.IsDirty())
+	assert.Equal([]profile.AttributeChange{{
+		Section:  global.NO_SECTION_KEY,
+		Key:      global.TEST_VAR_ATTRIBUTE_NAME_LABEL,
+		Type:     global.CHANGE_TYPE_MODIFIED,
+		OldValue: global.TEST_VAR_ATTRIBUTE_VALUE,
+		NewValue: global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED,
+	}}, testCredential.GetChanges())
+	assert.NoError(testCredential.Save())
+	assertModTime(t, testFactory.CredentialFile, savedTime, true)
+	assertModTime(t, testCredential.Profile.ConfigFileLocation, savedTime, false)
+
+	testCredential.Password = global.TEST_VAR_PASSWORD_ALTERNATE
+	assert.Equal([]profile.AttributeChange{{
+		Section:  "",
+		Key:      global.PASSWORD_LABEL,
+		Type:     global.CHANGE_TYPE_MODIFIED,
+		OldValue: global.TEST_VAR_PASSWORD,
+		NewValue: global.TEST_VAR_PASSWORD_ALTERNATE,
+	}}, testCredential.GetChanges())
+	assert.NoError(testCredential.Save())
+	assertModTime(t, testFactory.CredentialFile, savedTime, false)
+
+	loadedCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	assert.False(loadedCredential.IsDirty())
+	assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE, loadedCredential.Password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED, loadedCredential.GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+
+	assert.NoError(testFactory.SetOutputType(global.OUTPUT_TYPE_JSON))
+	assert.True(loadedCredential.IsDirty())
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialSaveConflict(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing saving a credential that was changed on disk since it was loaded.")
+	testCredential, newErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.NoError(testCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.NoError(testCredential.Section(global.TEST_VAR_SECOND_SECTION_KEY).SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.NoError(testCredential.Save())
+
+	// Saving another profile changes the shared credentials file, but is not a conflict.
+	otherCredential, newErr := NewProfile(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory, global.TEST_VAR_USERNAME_ALTERNATE, global.TEST_VAR_PASSWORD_ALTERNATE)
+	assert.NoError(newErr)
+	assert.NoError(otherCredential.Save())
+
+	// Someone else edits the first section and adds a key.
+	diskCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	assert.NoError(diskCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, "their value"))
+	assert.NoError(diskCredential.SetAttribute("their_key", "their value"))
+	assert.NoError(diskCredential.Save())
+
+	assert.NoError(testCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, "my value"))
+	assert.NoError(testCredential.Section(global.TEST_VAR_SECOND_SECTION_KEY).SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, "my value"))
+	assert.EqualError(testCredential.Save(), ERR_SAVE_CONFLICT)
+
+	conflicts, mergeErr := testCredential.MergeFromDisk()
+	assert.NoError(mergeErr)
+	assert.Equal([]profile.AttributeConflict{{
+		Section:    global.TEST_VAR_FIRST_SECTION_KEY,
+		Key:        global.TEST_VAR_ATTRIBUTE_NAME_LABEL,
+		BaseValue:  global.TEST_VAR_ATTRIBUTE_VALUE,
+		MyValue:    "my value",
+		TheirValue: "their value",
+	}}, conflicts)
+	assert.Equal("their value", testCredential.GetAttribute("their_key"))
+	assert.Equal("my value", testCredential.Section(global.TEST_VAR_SECOND_SECTION_KEY).GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+	assert.True(testCredential.IsDirty())
+	assert.NoError(testCredential.Save())
+
+	loadedCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	assert.Equal("my value", loadedCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+	assert.Equal("their value", loadedCredential.GetAttribute("their_key"))
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialWatch(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing watching a profile for changes.")
+
+	for _, options := range []WatchOptions{{}, {Poll: true, PollInterval: 10 * time.Millisecond}} {
+		log.Info().Msgf("Testing with polling set to %v.", options.Poll)
+		testCredential, newErr := NewProfile(global.TEST_VAR_SECOND_PROFILE_LABEL, testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+		assert.NoError(newErr)
+		assert.NoError(testCredential.Save())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		credentials, watchErr := WatchWithOptions(ctx, testFactory, global.TEST_VAR_SECOND_PROFILE_LABEL, options)
+		assert.NoError(watchErr)
+
+		// Saving another profile does not change the watched profile, so nothing should be delivered for it.
+		otherCredential, newErr := NewProfile(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+		assert.NoError(newErr)
+		assert.NoError(otherCredential.Save())
+		time.Sleep(50 * time.Millisecond)
+
+		testCredential.Password = global.TEST_VAR_PASSWORD_ALTERNATE
+		assert.NoError(testCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+		assert.NoError(testCredential.Save())
+
+		select {
+		case changedCredential := <-credentials:
+			assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE, changedCredential.Password)
+			assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, changedCredential.GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+		case <-time.After(5 * time.Second):
+			t.Fatal("the changed credential was not delivered")
+		}
+
+		cancel()
+
+		select {
+		case _, open := <-credentials:
+			assert.False(open)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the channel was not closed after the context was cancelled")
+		}
+
+		parentDirectoryCleanup(t)
+		testFactory, _ = factory.New(global.TEST_VAR_APPLICATION_NAME)
+	}
+
+	assert.NoError(testFactory.SetOutputType(global.OUTPUT_TYPE_ENV))
+	_, watchErr := Watch(context.Background(), testFactory, global.DEFAULT_PROFILE_NAME)
+	assert.EqualError(watchErr, ERR_WATCH_UNSUPPORTED_OUTPUT_TYPE)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialHistory(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing keeping and restoring previous versions of a profile.")
+	testCredential, newErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.NoError(testCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.NoError(testCredential.Save())
+
+	versions, listErr := ListVersions(testFactory, global.DEFAULT_PROFILE_NAME)
+	assert.NoError(listErr)
+	assert.Empty(versions)
+
+	// The first save with history enabled also keeps the version that was loaded.
+	testFactory.HistorySize = 3
+	loadedCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	loadedCredential.Password = global.TEST_VAR_PASSWORD_ALTERNATE
+	assert.NoError(loadedCredential.Save())
+
+	versions, listErr = ListVersions(testFactory, global.DEFAULT_PROFILE_NAME)
+	assert.NoError(listErr)
+	assert.Len(versions, 2)
+	assert.Equal(global.TEST_VAR_PASSWORD, versions[0].Password)
+	assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE, versions[1].Password)
+
+	assert.NoError(loadedCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED))
+	assert.NoError(loadedCredential.Save())
+	assert.NoError(loadedCredential.SetAttribute("another_key", global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.NoError(loadedCredential.Save())
+
+	versions, listErr = ListVersions(testFactory, global.DEFAULT_PROFILE_NAME)
+	assert.NoError(listErr)
+	assert.Len(versions, 3)
+	assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE, versions[0].Password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, versions[0].Attributes[global.NO_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+
+	changes, diffErr := DiffVersions(testFactory, global.DEFAULT_PROFILE_NAME, versions[0].ID, versions[2].ID)
+	assert.NoError(diffErr)
+	assert.Equal([]profile.AttributeChange{
+		{Section: global.NO_SECTION_KEY, Key: global.TEST_VAR_ATTRIBUTE_NAME_LABEL, Type: global.CHANGE_TYPE_MODIFIED, OldValue: global.TEST_VAR_ATTRIBUTE_VALUE, NewValue: global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED},
+		{Section: global.NO_SECTION_KEY, Key: "another_key", Type: global.CHANGE_TYPE_ADDED, OldValue: "", NewValue: global.TEST_VAR_ATTRIBUTE_VALUE},
+	}, changes)
+
+	_, diffErr = DiffVersions(testFactory, global.DEFAULT_PROFILE_NAME, versions[0].ID, "../credentials")
+	assert.EqualError(diffErr, ERR_HISTORY_VERSION_NOT_FOUND)
+
+	restoredCredential, restoreErr := RestoreVersion(testFactory, global.DEFAULT_PROFILE_NAME, versions[0].ID)
+	assert.NoError(restoreErr)
+	assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE, restoredCredential.Password)
+
+	loadedCredential, loadErr = Load(testFactory)
+	assert.NoError(loadErr)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, loadedCredential.GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+	assert.Equal("", loadedCredential.GetAttribute("another_key"))
+
+	versions, listErr = ListVersions(testFactory, global.DEFAULT_PROFILE_NAME)
+	assert.NoError(listErr)
+	assert.Len(versions, 3)
+	assert.Equal("", versions[2].Attributes[global.NO_SECTION_KEY]["another_key"])
+
+	historyInfo, statErr := os.Stat(testFactory.HistoryDirectory + global.DEFAULT_PROFILE_NAME + "/" + versions[2].ID + ".json")
+	assert.NoError(statErr)
+	assert.Equal(os.FileMode(0600), historyInfo.Mode().Perm())
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialHistoryNotRecorded(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing that a save succeeds when the profile cannot be added to the history.")
+	testFactory.HistorySize = 3
+	assert.NoError(os.MkdirAll(testFactory.HistoryDirectory, 0700))
+	assert.NoError(ioutil.WriteFile(testFactory.HistoryDirectory+global.DEFAULT_PROFILE_NAME, []byte{}, 0600))
+
+	testCredential, newErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.NoError(testCredential.Save())
+	assert.False(testCredential.IsDirty())
+
+	// The next save is not reported as a conflict with the previous one.
+	testCredential.Password = global.TEST_VAR_PASSWORD_ALTERNATE
+	assert.NoError(testCredential.Save())
+	loadedCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE, loadedCredential.Password)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialHistoryEnv(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing that profiles saved to the environment are not added to the history.")
+	testFactory.HistorySize = 3
+	assert.NoError(testFactory.SetOutputType(global.OUTPUT_TYPE_ENV))
+	testCredential, newErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.NoError(testCredential.Save())
+	defer testCredential.ClearEnv()
+
+	versions, listErr := ListVersions(testFactory, global.DEFAULT_PROFILE_NAME)
+	assert.NoError(listErr)
+	assert.Empty(versions)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialDiff(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing comparing credentials with each other and with what is on disk.")
+	fromCredential, newErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.NoError(fromCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+	toCredential, newErr := NewProfile(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD_ALTERNATE)
+	assert.NoError(newErr)
+	assert.NoError(toCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED))
+
+	passwordChange := profile.AttributeChange{
+		Section:  "",
+		Key:      global.PASSWORD_LABEL,
+		Type:     global.CHANGE_TYPE_MODIFIED,
+		OldValue: global.REDACTED_VALUE,
+		NewValue: global.REDACTED_VALUE,
+	}
+	attributeChange := profile.AttributeChange{
+		Section:  global.NO_SECTION_KEY,
+		Key:      global.TEST_VAR_ATTRIBUTE_NAME_LABEL,
+		Type:     global.CHANGE_TYPE_MODIFIED,
+		OldValue: global.TEST_VAR_ATTRIBUTE_VALUE,
+		NewValue: global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED,
+	}
+	changes := Diff(fromCredential, toCredential)
+	assert.Equal([]profile.AttributeChange{passwordChange, attributeChange}, changes)
+	assert.NotContains(profile.RenderChanges(changes), global.TEST_VAR_PASSWORD_ALTERNATE)
+
+	passwordChange.OldValue = global.TEST_VAR_PASSWORD
+	passwordChange.NewValue = global.TEST_VAR_PASSWORD_ALTERNATE
+	changes = DiffWithOptions(fromCredential, toCredential, DiffOptions{ShowSecrets: true})
+	assert.Equal([]profile.AttributeChange{passwordChange, attributeChange}, changes)
+
+	assert.NoError(fromCredential.Save())
+	changes, diffErr := fromCredential.DiffFromDisk()
+	assert.NoError(diffErr)
+	assert.Empty(changes)
+
+	diskCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	diskCredential.Password = global.TEST_VAR_PASSWORD_ALTERNATE
+	assert.NoError(diskCredential.Save())
+
+	changes, diffErr = fromCredential.DiffFromDisk()
+	assert.NoError(diffErr)
+	passwordChange.OldValue = global.REDACTED_VALUE
+	passwordChange.NewValue = global.REDACTED_VALUE
+	assert.Equal([]profile.AttributeChange{passwordChange}, changes)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialPrompt(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing prompting for a profile that is not stored.")
+	var output bytes.Buffer
+	options := PromptOptions{
+		Input:  strings.NewReader(global.TEST_VAR_USERNAME + "\n\n" + global.TEST_VAR_PASSWORD + "\n" + global.TEST_VAR_ATTRIBUTE_VALUE + "\n"),
+		Output: &output,
+		Attributes: []PromptAttribute{
+			{Section: global.TEST_VAR_FIRST_SECTION_KEY, Key: global.TEST_VAR_ATTRIBUTE_NAME_LABEL, Label: "Attribute"},
+		},
+	}
+	assert.NoError(testFactory.SetAlternates(global.TEST_VAR_USERNAME_ALTERNATE_LABEL, global.TEST_VAR_PASSWORD_ALTERNATE_LABEL))
+
+	promptedCredential, promptErr := LoadOrPromptWithOptions(global.DEFAULT_PROFILE_NAME, testFactory, options)
+	assert.NoError(promptErr)
+	assert.Equal(global.TEST_VAR_PASSWORD, promptedCredential.Password)
+	usernameLabel, passwordLabel := testFactory.GetAlternates()
+	assert.Equal(usernameLabel+": "+passwordLabel+": "+passwordLabel+": Attribute: ", output.String())
+
+	loadedCredential, loadErr := LoadOrPromptWithOptions(global.DEFAULT_PROFILE_NAME, testFactory, PromptOptions{Input: strings.NewReader("")})
+	assert.NoError(loadErr)
+	assert.Equal(global.TEST_VAR_USERNAME, loadedCredential.Username)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, loadedCredential.Section(global.TEST_VAR_FIRST_SECTION_KEY).GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+
+	_, promptErr = PromptWithOptions(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory, PromptOptions{Input: strings.NewReader(global.TEST_VAR_USERNAME + "\n"), Output: &output})
+	assert.EqualError(promptErr, ERR_PROMPT_INPUT_ENDED)
+
+	pipeReader, pipeWriter, pipeErr := os.Pipe()
+	assert.NoError(pipeErr)
+	defer pipeReader.Close()
+	defer pipeWriter.Close()
+	_, promptErr = PromptWithOptions(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory, PromptOptions{Input: pipeReader, Output: &output})
+	assert.EqualError(promptErr, ERR_PROMPT_NOT_A_TERMINAL)
+
+	profiles, listErr := serializer.ListProfiles(testFactory)
+	assert.NoError(listErr)
+	assert.Equal([]string{global.DEFAULT_PROFILE_NAME}, profiles)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialResolve(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing resolving a credential from a chain of sources.")
+	testFactory.SetEnvironment(map[string]string{global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL: global.TEST_VAR_USERNAME})
+	var output bytes.Buffer
+	promptOptions := PromptOptions{
+		Input:  strings.NewReader(global.TEST_VAR_USERNAME + "\n" + global.TEST_VAR_PASSWORD + "\n"),
+		Output: &output,
+	}
+
+	// The environment only has a username and nothing is stored yet, so only the prompt is complete.
+	resolvedCredential, resolvedSource, resolveErr := Resolve(testFactory, global.DEFAULT_PROFILE_NAME, Explicit(global.TEST_VAR_USERNAME, ""), Environment(), File(), PromptSource(promptOptions))
+	assert.NoError(resolveErr)
+	assert.Equal(global.SOURCE_PROMPT, resolvedSource.Name())
+	assert.Equal(global.TEST_VAR_PASSWORD, resolvedCredential.Password)
+
+	resolvedCredential, resolvedSource, resolveErr = Resolve(testFactory, global.DEFAULT_PROFILE_NAME, Environment(), File(), PromptSource(PromptOptions{Input: strings.NewReader("")}))
+	assert.NoError(resolveErr)
+	assert.Equal(global.SOURCE_FILE, resolvedSource.Name())
+	assert.Equal(global.TEST_VAR_PASSWORD, resolvedCredential.Password)
+
+	testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL] = global.TEST_VAR_PASSWORD_ALTERNATE
+	resolvedCredential, resolvedSource, resolveErr = Resolve(testFactory, global.DEFAULT_PROFILE_NAME, Environment(), File())
+	assert.NoError(resolveErr)
+	assert.Equal(global.SOURCE_ENVIRONMENT, resolvedSource.Name())
+	assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE, resolvedCredential.Password)
+	assert.Equal(global.OUTPUT_TYPE_INI, testFactory.OutputType)
+
+	resolvedCredential, resolvedSource, resolveErr = Resolve(testFactory, global.DEFAULT_PROFILE_NAME, Explicit(global.TEST_VAR_USERNAME_ALTERNATE, global.TEST_VAR_PASSWORD), Environment())
+	assert.NoError(resolveErr)
+	assert.Equal(global.SOURCE_EXPLICIT, resolvedSource.Name())
+	assert.Equal(global.TEST_VAR_USERNAME_ALTERNATE, resolvedCredential.Username)
+
+	_, _, resolveErr = Resolve(testFactory, global.TEST_VAR_FIRST_PROFILE_LABEL, Explicit("", ""), Environment(), File())
+	assert.EqualError(resolveErr, ERR_RESOLVE_NOT_FOUND)
+
+	_, _, resolveErr = Resolve(testFactory, global.TEST_VAR_FIRST_PROFILE_LABEL, File(), PromptSource(PromptOptions{Input: strings.NewReader("")}), Explicit(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD))
+	assert.EqualError(resolveErr, ERR_PROMPT_INPUT_ENDED)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialAws(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing saving and loading credentials with the aws output type.")
+	credentialFile := testFactory.ParentDirectory + "aws/credentials"
+	testFactory.SetEnvironment(map[string]string{
+		global.AWS_CREDENTIALS_FILE_ENVIRONMENT_KEY: credentialFile,
+		global.AWS_CONFIG_FILE_ENVIRONMENT_KEY:      testFactory.ParentDirectory + "aws/config",
+	})
+	assert.NoError(testFactory.SetOutputType(global.OUTPUT_TYPE_AWS))
+	testCredential, newErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.NoError(testCredential.SetAttribute("region", "eu-west-1"))
+	assert.NoError(testCredential.Save())
+
+	loadedCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	assert.Equal(global.TEST_VAR_PASSWORD, loadedCredential.Password)
+	assert.Equal("eu-west-1", loadedCredential.GetAttribute("region"))
+
+	assert.NoError(ioutil.WriteFile(credentialFile, []byte("[default]\naws_access_key_id = changed\naws_secret_access_key = changed\n"), 0600))
+	loadedCredential.Password = global.TEST_VAR_PASSWORD_ALTERNATE
+	assert.EqualError(loadedCredential.Save(), ERR_SAVE_CONFLICT)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialAwsHyphenatedProfile(t *testing.T) {
+	assert, log, testFactory := initTest(t)
+	log.Info().Msg("Testing loading and saving an AWS profile with a hyphen in its name.")
+	credentialFile, configFile := testFactory.ParentDirectory+"aws/credentials", testFactory.ParentDirectory+"aws/config"
+	testFactory.SetEnvironment(map[string]string{
+		global.AWS_CREDENTIALS_FILE_ENVIRONMENT_KEY: credentialFile,
+		global.AWS_CONFIG_FILE_ENVIRONMENT_KEY:      configFile,
+	})
+	assert.NoError(testFactory.SetOutputType(global.OUTPUT_TYPE_AWS))
+	assert.NoError(os.MkdirAll(testFactory.ParentDirectory+"aws", 0700))
+	assert.NoError(ioutil.WriteFile(credentialFile, []byte("[default]\naws_access_key_id = AKIADEFAULT\naws_secret_access_key = default_secret\n\n[prod-admin]\naws_access_key_id = AKIAPROD\naws_secret_access_key = prod_secret\n"), 0600))
+	assert.NoError(ioutil.WriteFile(configFile, []byte("[default]\nregion = us-east-1\n\n[profile prod-admin]\nsource_profile = default\nregion = eu-west-1\n"), 0600))
+
+	profiles, listErr := serializer.ListProfiles(testFactory)
+	assert.NoError(listErr)
+	assert.Equal([]string{global.DEFAULT_PROFILE_NAME, "prod-admin"}, profiles)
+
+	loadedCredential, loadErr := LoadFromProfile("prod-admin", testFactory)
+	assert.NoError(loadErr)
+	assert.Equal("AKIAPROD", loadedCredential.Username)
+	assert.Equal("default", loadedCredential.GetAttribute("source_profile"))
+
+	assert.NoError(loadedCredential.SetAttribute("region", "eu-west-2"))
+	assert.NoError(loadedCredential.Save())
+	configContents, readErr := ioutil.ReadFile(configFile)
+	assert.NoError(readErr)
+	assert.Equal("[default]\nregion = us-east-1\n\n[profile prod-admin]\nsource_profile = default\nregion = eu-west-2\n", string(configContents))
+
+	newCredential, newErr := NewProfile("prod.readonly", testFactory, "AKIAREAD", "read_secret")
+	assert.NoError(newErr)
+	assert.NoError(newCredential.SetAttribute("source_profile", "prod-admin"))
+	assert.NoError(newCredential.Save())
+	reloadedCredential, reloadErr := LoadFromProfile("prod.readonly", testFactory)
+	assert.NoError(reloadErr)
+	assert.Equal("prod-admin", reloadedCredential.GetAttribute("source_profile"))
+
+	// Other output types still only allow letters, numbers and underscores.
+	assert.NoError(testFactory.SetOutputType(global.OUTPUT_TYPE_INI))
+	_, newErr = NewProfile("prod-admin", testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.EqualError(newErr, profile.ERR_PROFILE_NAME_MUST_MATCH_REGEX)
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialTransport(t *testing.T) {
+	assert, _, testFactory := initTest(t)
+	var received []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		received = append(received, request)
+	}))
+	defer server.Close()
+
+	testCredential, credErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(credErr)
+	assert.NoError(testCredential.SetAttribute("token", "first-token"))
+
+	_, schemeErr := Transport(nil, testCredential, AuthScheme{Type: "digest"})
+	assert.EqualError(schemeErr, ERR_AUTH_SCHEME_INVALID)
+	_, valueErr := Transport(nil, testCredential, BearerAuth("missing"))
+	assert.EqualError(valueErr, ERR_AUTH_VALUE_NOT_SET)
+	blankCredential, blankErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(blankErr)
+	blankCredential.Password = ""
+	_, basicValueErr := Transport(nil, blankCredential, BasicAuth())
+	assert.EqualError(basicValueErr, ERR_AUTH_VALUE_NOT_SET)
+	blankCredential.Username, blankCredential.Password = "", global.TEST_VAR_PASSWORD
+	_, basicValueErr = Transport(nil, blankCredential, BasicAuth())
+	assert.EqualError(basicValueErr, ERR_AUTH_VALUE_NOT_SET)
+
+	basicTransport, basicErr := Transport(nil, testCredential, BasicAuth())
+	assert.NoError(basicErr)
+	request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	_, getErr := (&http.Client{Transport: basicTransport}).Do(request)
+	assert.NoError(getErr)
+	username, password, hasBasic := received[0].BasicAuth()
+	assert.True(hasBasic)
+	assert.Equal(global.TEST_VAR_USERNAME, username)
+	assert.Equal(global.TEST_VAR_PASSWORD, password)
+	assert.Empty(request.Header.Get("Authorization"))
+
+	bearerTransport, bearerErr := Transport(nil, testCredential, BearerAuth("token"))
+	assert.NoError(bearerErr)
+	_, getErr = (&http.Client{Transport: bearerTransport}).Get(server.URL)
+	assert.NoError(getErr)
+	assert.Equal("Bearer first-token", received[1].Header.Get("Authorization"))
+
+	keyTransport, keyErr := Transport(nil, testCredential, ApiKeyAuth("", ""))
+	assert.NoError(keyErr)
+	_, getErr = (&http.Client{Transport: keyTransport}).Get(server.URL)
+	assert.NoError(getErr)
+	assert.Equal(global.TEST_VAR_PASSWORD, received[2].Header.Get(global.DEFAULT_API_KEY_HEADER))
+	assert.Empty(received[2].Header.Get("Authorization"))
+
+	updates := make(chan *Credential)
+	bearerTransport.Follow(updates)
+	updatedCredential, _ := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(updatedCredential.SetAttribute("token", "second-token"))
+	updates <- updatedCredential
+	close(updates)
+	assert.Eventually(func() bool { return bearerTransport.GetCredential() == updatedCredential }, time.Second, 10*time.Millisecond)
+	_, getErr = (&http.Client{Transport: bearerTransport}).Get(server.URL)
+	assert.NoError(getErr)
+	assert.Equal("Bearer second-token", received[3].Header.Get("Authorization"))
+
+	assert.NoError(updatedCredential.SetAttribute("token", "saved-token"))
+	assert.NoError(updatedCredential.Save())
+	assert.NoError(bearerTransport.Reload())
+	_, getErr = (&http.Client{Transport: bearerTransport}).Get(server.URL)
+	assert.NoError(getErr)
+	assert.Equal("Bearer saved-token", received[4].Header.Get("Authorization"))
+	parentDirectoryCleanup(t)
+}
+
+func TestCredentialOAuth2TokenSource(t *testing.T) {
+	assert, _, testFactory := initTest(t)
+	refreshCount := 0
+	currentRefresh := "refresh-0"
+	onRefresh := func() {}
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		clientId, clientSecret, _ := request.BasicAuth()
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+
+		if clientId != global.TEST_VAR_USERNAME || clientSecret != global.TEST_VAR_PASSWORD ||
+			request.FormValue("grant_type") != "refresh_token" || request.FormValue("refresh_token") != currentRefresh {
+			writer.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(writer).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		// Each refresh token can only be used once, as with servers that rotate them.
+		onRefresh()
+		refreshCount++
+		currentRefresh = "refresh-" + strconv.Itoa(refreshCount)
+		var expiresIn interface{} = 3600
+
+		// Some servers send expires_in as a string.
+		if refreshCount%2 == 0 {
+			expiresIn = "3600"
+		}
+
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"access_token":  "access-" + strconv.Itoa(refreshCount),
+			"token_type":    "bearer",
+			"refresh_token": currentRefresh,
+			"expires_in":    expiresIn,
+		})
+	}))
+	defer tokenServer.Close()
+	var received []*http.Request
+	apiServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		received = append(received, request)
+	}))
+	defer apiServer.Close()
+
+	testCredential, credErr := New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(credErr)
+	_, urlErr := NewTokenSource(testCredential)
+	assert.EqualError(urlErr, ERR_OAUTH2_TOKEN_URL_NOT_SET)
+	assert.NoError(testCredential.Section(global.OAUTH2_SECTION_NAME).SetAttribute(global.OAUTH2_TOKEN_URL_KEY, tokenServer.URL))
+	assert.NoError(testCredential.SetOAuth2Token(&Token{
+		AccessToken:  "access-0",
+		RefreshToken: "refresh-0",
+		Expiry:       time.Now().Add(-time.Minute),
+	}))
+	assert.NoError(testCredential.Save())
+	otherCredential, otherErr := Load(testFactory)
+	assert.NoError(otherErr)
+
+	tokenSource, sourceErr := NewTokenSource(testCredential)
+	assert.NoError(sourceErr)
+	_, getErr := (&http.Client{Transport: tokenSource.Transport(nil)}).Get(apiServer.URL)
+	assert.NoError(getErr)
+	assert.Equal("Bearer access-1", received[0].Header.Get("Authorization"))
+	_, getErr = (&http.Client{Transport: tokenSource.Transport(nil)}).Get(apiServer.URL)
+	assert.NoError(getErr)
+	assert.Equal("Bearer access-1", received[1].Header.Get("Authorization"))
+	assert.Equal(1, refreshCount)
+
+	// The rotated refresh token is saved, so it is used after a restart.
+	loadedCredential, loadErr := Load(testFactory)
+	assert.NoError(loadErr)
+	loadedToken, tokenErr := loadedCredential.GetOAuth2Token()
+	assert.NoError(tokenErr)
+	assert.Equal("access-1", loadedToken.AccessToken)
+	assert.Equal("refresh-1", loadedToken.RefreshToken)
+	assert.WithinDuration(time.Now().Add(time.Hour), loadedToken.Expiry, time.Minute)
+
+	// Another process that loaded the expired token uses the refreshed one rather than spending its refresh token again.
+	otherSource, otherSourceErr := NewTokenSource(otherCredential)
+	assert.NoError(otherSourceErr)
+	sharedToken, sharedErr := otherSource.Token()
+	assert.NoError(sharedErr)
+	assert.Equal("access-1", sharedToken.AccessToken)
+	assert.Equal(1, refreshCount)
+
+	// If the profile is changed on disk while refreshing, the changes are merged in and the rotated token is still saved.
+	onRefresh = func() {
+		assert.NoError(otherCredential.SetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+		assert.NoError(otherCredential.Save())
+	}
+	_, refreshErr := tokenSource.Refresh()
+	assert.NoError(refreshErr)
+	onRefresh = func() {}
+	loadedCredential, loadErr = Load(testFactory)
+	assert.NoError(loadErr)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, loadedCredential.GetAttribute(global.TEST_VAR_ATTRIBUTE_NAME_LABEL))
+	loadedToken, tokenErr = loadedCredential.GetOAuth2Token()
+	assert.NoError(tokenErr)
+	assert.Equal("access-2", loadedToken.AccessToken)
+	assert.Equal("refresh-2", loadedToken.RefreshToken)
+	assert.WithinDuration(time.Now().Add(time.Hour), loadedToken.Expiry, time.Minute)
+
+	// A refreshed token that cannot be saved is still used while it is valid, and is saved once the disk is fixed.
+	configFile := testCredential.Profile.ConfigFileLocation
+	onRefresh = func() {
+		assert.NoError(os.Rename(configFile, configFile+".bak"))
+		assert.NoError(os.Mkdir(configFile, 0700))
+	}
+	_, refreshErr = tokenSource.Refresh()
+	assert.Error(refreshErr)
+	onRefresh = func() {}
+	_, getErr = (&http.Client{Transport: tokenSource.Transport(nil)}).Get(apiServer.URL)
+	assert.NoError(getErr)
+	assert.Equal("Bearer access-3", received[2].Header.Get("Authorization"))
+	assert.NoError(os.Remove(configFile))
+	assert.NoError(os.Rename(configFile+".bak", configFile))
+	unsavedToken, unsavedErr := tokenSource.Token()
+	assert.NoError(unsavedErr)
+	assert.Equal("access-3", unsavedToken.AccessToken)
+	loadedCredential, loadErr = Load(testFactory)
+	assert.NoError(loadErr)
+	loadedToken, tokenErr = loadedCredential.GetOAuth2Token()
+	assert.NoError(tokenErr)
+	assert.Equal("refresh-3", loadedToken.RefreshToken)
+
+	assert.NoError(loadedCredential.SetOAuth2Token(&Token{AccessToken: "unknown-access", RefreshToken: "unknown-refresh"}))
+	loadedSource, loadedSourceErr := NewTokenSource(loadedCredential)
+	assert.NoError(loadedSourceErr)
+	unexpiredToken, unexpiredErr := loadedSource.Token()
+	assert.NoError(unexpiredErr)
+	assert.Equal("unknown-access", unexpiredToken.AccessToken)
+	_, refreshErr = loadedSource.Refresh()
+	assert.EqualError(refreshErr, ERR_OAUTH2_REFRESH_FAILED)
+
+	assert.NoError(loadedCredential.SetOAuth2Token(&Token{AccessToken: "unknown-access", Expiry: time.Now()}))
+	_, noRefreshErr := loadedSource.Token()
+	assert.EqualError(noRefreshErr, ERR_OAUTH2_NO_REFRESH_TOKEN)
+	requestBody := &closeRecorder{Reader: strings.NewReader("body")}
+	failedRequest, requestErr := http.NewRequest(http.MethodPost, apiServer.URL, requestBody)
+	assert.NoError(requestErr)
+	_, transportErr := loadedSource.Transport(nil).RoundTrip(failedRequest)
+	assert.EqualError(transportErr, ERR_OAUTH2_NO_REFRESH_TOKEN)
+	assert.True(requestBody.closed)
+	assert.Equal(3, refreshCount)
+	parentDirectoryCleanup(t)
+}
+
+// closeRecorder is a request body that records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (thisRecorder *closeRecorder) Close() error {
+	thisRecorder.closed = true
+	return nil
+}
+
+func assertModTime(t *testing.T, fileName string, modTime time.Time, expectEqual bool) {
+	fileInfo, statErr := os.Stat(fileName)
+
+	if statErr != nil {
+		t.Fatal(statErr)
+	}
+
+	if fileInfo.ModTime().Equal(modTime) != expectEqual {
+		t.Errorf("%s was modified at %v, compared to %v", fileName, fileInfo.ModTime(), modTime)
+	}
+}
+
 func initTest(t *testing.T) (*as.Assertions, zerolog.Logger, *factory.Factory) {
 	assert, log := global.InitTest(t)
 	testFactory, tfErr := factory.New(global.TEST_VAR_APPLICATION_NAME)
@@ -0,0 +1,110 @@
+package credential
+
+import (
+	"errors"
+
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/profile"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+/*
+DiffOptions changes the behaviour of DiffWithOptions and DiffFromDiskWithOptions.
+
+ShowSecrets: include the username and password values in the changes, rather than global.REDACTED_VALUE.
+*/
+type DiffOptions struct {
+	ShowSecrets bool
+}
+
+/*
+Diff compares two Credentials using the default DiffOptions, so username and password values are redacted. See
+DiffWithOptions.
+*/
+func Diff(fromCredential *Credential, toCredential *Credential) []profile.AttributeChange {
+	return DiffWithOptions(fromCredential, toCredential, DiffOptions{})
+}
+
+/*
+DiffWithOptions lists what changed going from one Credential to another, such as two profiles or two copies of the same
+profile. A changed username or password is reported first with a blank Section, followed by the attributes that were
+added, deleted or modified in each section (see profile.Diff). The changes can be formatted as text with
+profile.RenderChanges.
+*/
+func DiffWithOptions(fromCredential *Credential, toCredential *Credential, options DiffOptions) []profile.AttributeChange {
+	changes := make([]profile.AttributeChange, 0)
+
+	if fromCredential.Username != toCredential.Username {
+		changes = append(changes, credentialChange(global.USERNAME_LABEL, fromCredential.Username, toCredential.Username, options))
+	}
+
+	if fromCredential.Password != toCredential.Password {
+		changes = append(changes, credentialChange(global.PASSWORD_LABEL, fromCredential.Password, toCredential.Password, options))
+	}
+
+	return append(changes, profile.Diff(fromCredential.Profile, toCredential.Profile)...)
+}
+
+/*
+DiffFromDisk compares what is stored on disk for the Credential's profile with the Credential, using the default
+DiffOptions. See DiffFromDiskWithOptions.
+*/
+func (thisCredential *Credential) DiffFromDisk() ([]profile.AttributeChange, error) {
+	return thisCredential.DiffFromDiskWithOptions(DiffOptions{})
+}
+
+/*
+DiffFromDiskWithOptions lists what saving the Credential would change, by comparing what is currently stored for its
+profile (using the Factory's output type) with the Credential. Unlike GetChanges, this also picks up changes made on disk
+by something else since the Credential was loaded.
+*/
+func (thisCredential *Credential) DiffFromDiskWithOptions(options DiffOptions) ([]profile.AttributeChange, error) {
+	if !thisCredential.Factory.Initialized || !thisCredential.Initialized {
+		return nil, errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	mySerializer := serializer.New(thisCredential.Factory, thisCredential.Profile.Name)
+	username, password, attributes, loadErr := mySerializer.Deserialize()
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	diskProfile, profileErr := profile.New(thisCredential.Profile.Name, thisCredential.Factory)
+
+	if profileErr != nil {
+		return nil, profileErr
+	}
+
+	replaceErr := replaceAttributes(diskProfile, attributes)
+
+	if replaceErr != nil {
+		return nil, replaceErr
+	}
+
+	diskCredential := &Credential{
+		Username:    username,
+		Password:    password,
+		Initialized: true,
+		Factory:     thisCredential.Factory,
+		Profile:     diskProfile,
+	}
+
+	return DiffWithOptions(diskCredential, thisCredential, options), nil
+}
+
+// credentialChange describes a changed username or password, which is added if it was blank before.
+func credentialChange(key string, oldValue string, newValue string, options DiffOptions) profile.AttributeChange {
+	change := profile.AttributeChange{Section: "", Key: key, Type: global.CHANGE_TYPE_MODIFIED, OldValue: oldValue, NewValue: newValue}
+
+	if oldValue == "" {
+		change.Type = global.CHANGE_TYPE_ADDED
+	}
+
+	if !options.ShowSecrets {
+		change.OldValue = global.REDACTED_VALUE
+		change.NewValue = global.REDACTED_VALUE
+	}
+
+	return change
+}
@@ -8,3 +8,20 @@ const ERR_CANNOT_SET_USERNAME_WHEN_USING_SECTION = "you cannot set username via
 const ERR_CANNOT_SET_PASSWORD_WHEN_USING_SECTION = "you cannot set password via this method when using the Section() method"
 const ERR_CANNOT_REMOVE_USERNAME = "you cannot remove the username from the Credential"
 const ERR_CANNOT_REMOVE_PASSWORD = "you cannot remove the username from the Credential"
+const ERR_SAVE_CONFLICT = "sorry the credential has been changed on disk since it was loaded, use MergeFromDisk to merge the changes before saving"
+const ERR_WATCH_UNSUPPORTED_OUTPUT_TYPE = "sorry only file output types can be watched, valid values are (ini, json, aws)"
+const ERR_HISTORY_VERSION_NOT_FOUND = "sorry that version of the profile could not be found in the history"
+const ERR_HISTORY_NOT_RECORDED = "the profile was saved but could not be added to the history"
+const ERR_PROMPT_NOT_A_TERMINAL = "sorry credentials can only be prompted for when standard input is a terminal, set them with Save or the go-credentials command instead"
+const ERR_PROMPT_UNSUPPORTED_PLATFORM = "sorry reading credentials from a terminal is not supported on this platform, use a Reader for PromptOptions.Input instead"
+const ERR_PROMPT_INPUT_ENDED = "sorry the input ended before every credential was entered"
+const ERR_SOURCE_INCOMPLETE = "sorry the source does not have a complete credential for the profile"
+const ERR_RESOLVE_NOT_FOUND = "sorry none of the sources had a complete credential for the profile"
+const ERR_AUTH_SCHEME_INVALID = "sorry the authentication scheme is not valid, valid values are (basic, bearer, api_key)"
+const ERR_AUTH_VALUE_NOT_SET = "sorry the credential does not have a value for the authentication scheme"
+const ERR_OAUTH2_TOKEN_URL_NOT_SET = "sorry the token endpoint must be given, or stored in the oauth2 section as token_url"
+const ERR_OAUTH2_NO_REFRESH_TOKEN = "sorry the access token has expired and there is no refresh token to renew it with"
+const ERR_OAUTH2_REFRESH_FAILED = "sorry the token endpoint did not return a new access token"
+const ERR_OAUTH2_TOKEN_NOT_SAVED = "the refreshed oauth2 token could not be saved, it will be saved again on next use"
+const ERR_OAUTH2_INVALID_EXPIRY = "sorry the stored oauth2 expiry is not a valid RFC 3339 time"
+const ERR_PROFILE_NAMES_TAKEN = "sorry every profile name that could be used is already taken by another profile"
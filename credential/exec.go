@@ -0,0 +1,131 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+/*
+Command builds an exec.Cmd for name and args whose environment is the environment of the current process plus the
+Credential and its Profile, using the same variable names as the env output type. The variables are only set on the
+child, the environment of the current process is never modified.
+*/
+func (thisCredential *Credential) Command(ctx context.Context, name string, args ...string) (*exec.Cmd, error) {
+	if !thisCredential.Initialized || !thisCredential.Profile.Initialized {
+		return nil, errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	mySerializer := serializer.New(thisCredential.Factory, thisCredential.Profile.Name)
+	username, password, attributes := thisCredential.Serialize()
+	variables := mySerializer.GetEnvVariables(username, password, attributes)
+
+	//#nosec
+	command := exec.CommandContext(ctx, name, args...)
+	command.Env = mergeEnvironment(os.Environ(), variables)
+	return command, nil
+}
+
+/*
+Exec runs name with args as a child process with the Credential injected into its environment (see Command). The child
+shares the standard input, output and error of the current process, and the signals received while it runs are
+forwarded to it: interrupt, terminate, hangup and quit on unix, and interrupt elsewhere. When the child exits, its exit
+code is returned; on unix a child killed by a signal returns 128 plus the signal number, as a shell would. An error is
+only returned if the child could not be run, or if ctx was cancelled before it finished.
+*/
+func (thisCredential *Credential) Exec(ctx context.Context, name string, args ...string) (int, error) {
+	command, commandErr := thisCredential.Command(ctx, name, args...)
+
+	if commandErr != nil {
+		return -1, commandErr
+	}
+
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	thisCredential.Factory.Log.Debug().Str("command", name).Msg("Starting child process.")
+	startErr := command.Start()
+
+	if startErr != nil {
+		thisCredential.Factory.Log.Error().Err(startErr).Str("command", name).Msg("Error starting child process.")
+		return -1, startErr
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case received := <-signals:
+				thisCredential.Factory.Log.Trace().Str("signal", received.String()).Msg("Forwarding signal to child process.")
+				_ = command.Process.Signal(received)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	waitErr := command.Wait()
+
+	if ctx.Err() != nil {
+		return -1, ctx.Err()
+	}
+
+	if waitErr != nil {
+		exitErr, isExitErr := waitErr.(*exec.ExitError)
+
+		if !isExitErr {
+			return -1, waitErr
+		}
+
+		exitCode := getExitCode(exitErr)
+		thisCredential.Factory.Log.Debug().Int("exit_code", exitCode).Msg("Child process exited.")
+		return exitCode, nil
+	}
+
+	thisCredential.Factory.Log.Debug().Int("exit_code", 0).Msg("Child process exited.")
+	return 0, nil
+}
+
+// mergeEnvironment overrides or adds variables to an environment in the os.Environ() format.
+func mergeEnvironment(environment []string, variables map[string]string) []string {
+	merged := make([]string, 0, len(environment)+len(variables))
+
+	for _, entry := range environment {
+		splitIndex := strings.Index(entry, "=")
+
+		if splitIndex >= 0 {
+			if _, overridden := variables[entry[:splitIndex]]; overridden {
+				continue
+			}
+		}
+
+		merged = append(merged, entry)
+	}
+
+	keys := make([]string, 0, len(variables))
+
+	for key := range variables {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		merged = append(merged, key+"="+variables[key])
+	}
+
+	return merged
+}
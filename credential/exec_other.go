@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !solaris && !aix && !windows
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd,!solaris,!aix,!windows
+
+package credential
+
+import (
+	"os"
+	"os/exec"
+)
+
+// forwardedSignals are passed on to the child process started by Exec rather than handled by the parent.
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// getExitCode returns the exit code of a child process.
+func getExitCode(exitErr *exec.ExitError) int {
+	return exitErr.ProcessState.ExitCode()
+}
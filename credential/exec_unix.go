@@ -0,0 +1,22 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd || solaris || aix
+// +build linux darwin dragonfly freebsd netbsd openbsd solaris aix
+
+package credential
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// forwardedSignals are passed on to the child process started by Exec rather than handled by the parent.
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// getExitCode returns the exit code of a child process, or 128 plus the signal number if it was killed by a signal.
+func getExitCode(exitErr *exec.ExitError) int {
+	if status, isStatus := exitErr.Sys().(syscall.WaitStatus); isStatus && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+
+	return exitErr.ExitCode()
+}
@@ -0,0 +1,21 @@
+//go:build windows
+// +build windows
+
+package credential
+
+import (
+	"os"
+	"os/exec"
+)
+
+/*
+forwardedSignals are passed on to the child process started by Exec rather than handled by the parent. A console
+interrupt already reaches the child through the console it shares with the parent, so it is only caught here to keep
+the parent running until the child exits.
+*/
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// getExitCode returns the exit code of a child process.
+func getExitCode(exitErr *exec.ExitError) int {
+	return exitErr.ProcessState.ExitCode()
+}
@@ -0,0 +1,288 @@
+package credential
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/profile"
+)
+
+const historyVersionFormat = "20060102T150405.000000000Z"
+const historyFileExtension = ".json"
+
+/*
+Version is a copy of a profile as it was saved at one point in time, kept in the history when Factory.HistorySize is
+greater than 0. ID identifies the version and sorts in the order the versions were saved.
+*/
+type Version struct {
+	ID         string                       `json:"id" yaml:"id"`
+	SavedAt    time.Time                    `json:"saved_at" yaml:"saved_at"`
+	Username   string                       `json:"username" yaml:"username"`
+	Password   string                       `json:"password" yaml:"password"`
+	Attributes map[string]map[string]string `json:"attributes" yaml:"attributes"`
+}
+
+/*
+ListVersions returns the versions of a profile kept in the history, oldest first. A profile without any history returns
+an empty list.
+
+History is opt-in: when Factory.HistorySize is greater than 0, every Credential.Save adds the saved profile to
+HistoryDirectory/<profile>/ and removes the oldest versions beyond HistorySize. The first time a loaded Credential is
+saved, the version it was loaded from is kept as well, so the values before the save can always be restored. Profiles
+saved with the env output type are not kept. Each version is a json file written with 0600 permissions, the same as the
+credentials file, and like the credentials file it is not encrypted.
+*/
+func ListVersions(sourceFactory *factory.Factory, profileName string) ([]Version, error) {
+	if !sourceFactory.Initialized {
+		return nil, errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	historyDirectory := getHistoryDirectory(sourceFactory, profileName)
+	files, readErr := ioutil.ReadDir(historyDirectory)
+	versions := make([]Version, 0)
+
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return versions, nil
+		}
+
+		return nil, readErr
+	}
+
+	for _, file := range files {
+		if !file.Mode().IsRegular() || !strings.HasSuffix(file.Name(), historyFileExtension) {
+			continue
+		}
+
+		version, loadErr := loadVersion(filepath.Join(historyDirectory, file.Name()))
+
+		if loadErr != nil {
+			return nil, loadErr
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i int, j int) bool {
+		return versions[i].ID < versions[j].ID
+	})
+
+	return versions, nil
+}
+
+/*
+DiffVersions lists the changes between two versions of a profile in the history, in the same format as
+Credential.GetChanges. The values are not redacted.
+*/
+func DiffVersions(sourceFactory *factory.Factory, profileName string, fromID string, toID string) ([]profile.AttributeChange, error) {
+	fromVersion, fromErr := GetVersion(sourceFactory, profileName, fromID)
+
+	if fromErr != nil {
+		return nil, fromErr
+	}
+
+	toVersion, toErr := GetVersion(sourceFactory, profileName, toID)
+
+	if toErr != nil {
+		return nil, toErr
+	}
+
+	changes := make([]profile.AttributeChange, 0)
+
+	if fromVersion.Username != toVersion.Username {
+		changes = append(changes, profile.AttributeChange{Section: "", Key: global.USERNAME_LABEL, Type: global.CHANGE_TYPE_MODIFIED, OldValue: fromVersion.Username, NewValue: toVersion.Username})
+	}
+
+	if fromVersion.Password != toVersion.Password {
+		changes = append(changes, profile.AttributeChange{Section: "", Key: global.PASSWORD_LABEL, Type: global.CHANGE_TYPE_MODIFIED, OldValue: fromVersion.Password, NewValue: toVersion.Password})
+	}
+
+	return append(changes, profile.DiffAttributes(fromVersion.Attributes, toVersion.Attributes)...), nil
+}
+
+/*
+GetVersion returns one version of a profile from the history, or ERR_HISTORY_VERSION_NOT_FOUND if there is no version
+with that ID.
+*/
+func GetVersion(sourceFactory *factory.Factory, profileName string, versionID string) (Version, error) {
+	if !sourceFactory.Initialized {
+		return Version{}, errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	if strings.ContainsAny(versionID, `/\`) || versionID == "" {
+		return Version{}, errors.New(ERR_HISTORY_VERSION_NOT_FOUND)
+	}
+
+	versionFile := filepath.Join(getHistoryDirectory(sourceFactory, profileName), versionID+historyFileExtension)
+	version, loadErr := loadVersion(versionFile)
+
+	if os.IsNotExist(loadErr) {
+		sourceFactory.Log.Error().Str("profile", profileName).Str("version", versionID).Msg(ERR_HISTORY_VERSION_NOT_FOUND)
+		return Version{}, errors.New(ERR_HISTORY_VERSION_NOT_FOUND)
+	}
+
+	return version, loadErr
+}
+
+/*
+RestoreVersion saves a version of a profile from the history as the current profile, using the Factory's output type,
+and returns the restored Credential. The restore is saved like any other change, so it is also added to the history and
+can itself be undone.
+*/
+func RestoreVersion(sourceFactory *factory.Factory, profileName string, versionID string) (*Credential, error) {
+	version, versionErr := GetVersion(sourceFactory, profileName, versionID)
+
+	if versionErr != nil {
+		return nil, versionErr
+	}
+
+	// Loading the current profile first means attributes added since the version was saved are removed by Save.
+	restoredCredential, loadErr := LoadFromProfile(profileName, sourceFactory)
+
+	if loadErr != nil {
+		restoredCredential, loadErr = NewProfile(profileName, sourceFactory, version.Username, version.Password)
+
+		if loadErr != nil {
+			return nil, loadErr
+		}
+	}
+
+	replaceErr := replaceAttributes(restoredCredential.Profile, version.Attributes)
+
+	if replaceErr != nil {
+		return nil, replaceErr
+	}
+
+	restoredCredential.Username = version.Username
+	restoredCredential.Password = version.Password
+	sourceFactory.Log.Info().Str("profile", profileName).Str("version", versionID).Msg("Restoring profile from history.")
+	saveErr := restoredCredential.Save()
+
+	if saveErr != nil {
+		return nil, saveErr
+	}
+
+	return restoredCredential, nil
+}
+
+/*
+recordHistory adds the Credential to the history of its profile, if history is enabled. previous is the version the
+Credential was loaded from, which is kept first if the profile does not have any history yet. Credentials saved to the
+environment are not written to disk, so they are not added to the history either.
+*/
+func (thisCredential *Credential) recordHistory(previous *Version) error {
+	if thisCredential.Factory.HistorySize <= 0 || thisCredential.Factory.OutputType == global.OUTPUT_TYPE_ENV {
+		return nil
+	}
+
+	versions, listErr := ListVersions(thisCredential.Factory, thisCredential.Profile.Name)
+
+	if listErr != nil {
+		return listErr
+	}
+
+	if len(versions) == 0 && previous != nil {
+		if writeErr := thisCredential.writeVersion(*previous); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	savedAt := time.Now().UTC()
+	writeErr := thisCredential.writeVersion(Version{
+		ID:         savedAt.Format(historyVersionFormat),
+		SavedAt:    savedAt,
+		Username:   thisCredential.Username,
+		Password:   thisCredential.Password,
+		Attributes: thisCredential.Profile.GetSavedAttributes(),
+	})
+
+	if writeErr != nil {
+		return writeErr
+	}
+
+	return thisCredential.pruneHistory()
+}
+
+// savedVersion returns the Credential as it was last loaded or saved, or nil if it has never been.
+func (thisCredential *Credential) savedVersion() *Version {
+	if thisCredential.saved == nil {
+		return nil
+	}
+
+	// Subtracting a nanosecond keeps this version before the one being saved, even on a coarse clock.
+	savedAt := time.Now().UTC().Add(-time.Nanosecond)
+
+	return &Version{
+		ID:         savedAt.Format(historyVersionFormat),
+		SavedAt:    savedAt,
+		Username:   thisCredential.saved.username,
+		Password:   thisCredential.saved.password,
+		Attributes: thisCredential.Profile.GetSavedAttributes(),
+	}
+}
+
+func (thisCredential *Credential) writeVersion(version Version) error {
+	historyDirectory := getHistoryDirectory(thisCredential.Factory, thisCredential.Profile.Name)
+	mkErr := os.MkdirAll(historyDirectory, 0700)
+
+	if mkErr != nil {
+		return mkErr
+	}
+
+	versionJson, marshalErr := json.MarshalIndent(version, "", global.INDENT_JSON)
+
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	thisCredential.Factory.Log.Debug().Str("profile", thisCredential.Profile.Name).Str("version", version.ID).Msg("Adding version to history.")
+	return ioutil.WriteFile(filepath.Join(historyDirectory, version.ID+historyFileExtension), versionJson, 0600)
+}
+
+func (thisCredential *Credential) pruneHistory() error {
+	versions, listErr := ListVersions(thisCredential.Factory, thisCredential.Profile.Name)
+
+	if listErr != nil {
+		return listErr
+	}
+
+	historyDirectory := getHistoryDirectory(thisCredential.Factory, thisCredential.Profile.Name)
+
+	for len(versions) > thisCredential.Factory.HistorySize {
+		thisCredential.Factory.Log.Trace().Str("version", versions[0].ID).Msg("Removing old version from history.")
+		removeErr := os.Remove(filepath.Join(historyDirectory, versions[0].ID+historyFileExtension))
+
+		if removeErr != nil {
+			return removeErr
+		}
+
+		versions = versions[1:]
+	}
+
+	return nil
+}
+
+func loadVersion(fileName string) (Version, error) {
+	//#nosec
+	versionJson, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		return Version{}, readErr
+	}
+
+	var version Version
+	unmarshalErr := json.Unmarshal(versionJson, &version)
+	return version, unmarshalErr
+}
+
+func getHistoryDirectory(sourceFactory *factory.Factory, profileName string) string {
+	return filepath.Join(sourceFactory.HistoryDirectory, profileName)
+}
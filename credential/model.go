@@ -16,4 +16,16 @@ type Credential struct {
 	Profile              *profile.Profile
 	environmentVariables []string
 	selectedSection      string
+	saved                *savedCredential
+}
+
+// savedCredential records where and how a Credential was last loaded or saved, so Save can tell what has changed.
+type savedCredential struct {
+	outputType    string
+	profileName   string
+	usernameLabel string
+	passwordLabel string
+	username      string
+	password      string
+	fileHashes    string
 }
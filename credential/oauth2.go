@@ -0,0 +1,390 @@
+package credential
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+const defaultOAuth2ExpiryDelta = 10 * time.Second
+
+/*
+Token is an OAuth2 token, as stored in the oauth2 section of a Profile. Expiry is zero if the token does not expire.
+*/
+type Token struct {
+	AccessToken  string
+	TokenType    string
+	RefreshToken string
+	Expiry       time.Time
+}
+
+/*
+TokenSourceOptions changes the behaviour of NewTokenSourceWithOptions.
+
+TokenURL: the token endpoint used to refresh the token. Defaults to the token_url attribute of the oauth2 section.
+
+Client: the http.Client used to call the token endpoint. Defaults to http.DefaultClient.
+
+ExpiryDelta: how long before its expiry a token is refreshed, so that it does not expire while a request is being sent.
+Defaults to 10s.
+
+Scopes: the scopes asked for when refreshing. Defaults to the scopes of the original token.
+
+ClientCredentialsInBody: send the client ID and secret as client_id and client_secret form values rather than with HTTP
+basic authentication, for token endpoints that do not support the latter.
+*/
+type TokenSourceOptions struct {
+	TokenURL                string
+	Client                  *http.Client
+	ExpiryDelta             time.Duration
+	Scopes                  []string
+	ClientCredentialsInBody bool
+}
+
+/*
+TokenSource provides a valid OAuth2 access token for a Credential, refreshing it when it expires. It is safe to use
+from multiple goroutines.
+*/
+type TokenSource struct {
+	credential *Credential
+	options    TokenSourceOptions
+	mutex      sync.Mutex
+	unsaved    bool
+}
+
+/*
+tokenResponse is the body of a successful or failed response from a token endpoint (RFC 6749 sections 5.1 and 5.2).
+ExpiresIn is a json.Number as some servers send it as a string.
+*/
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	RefreshToken     string      `json:"refresh_token"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+type tokenTransport struct {
+	source *TokenSource
+	base   http.RoundTripper
+}
+
+/*
+GetOAuth2Token reads the Token stored in the oauth2 section of the Credential's Profile. The Token is empty if none has
+been stored.
+*/
+func (thisCredential *Credential) GetOAuth2Token() (*Token, error) {
+	if !thisCredential.Initialized {
+		return nil, errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	oauth2Section := thisCredential.Section(global.OAUTH2_SECTION_NAME)
+	myToken := &Token{
+		AccessToken:  oauth2Section.GetAttribute(global.OAUTH2_ACCESS_TOKEN_KEY),
+		TokenType:    oauth2Section.GetAttribute(global.OAUTH2_TOKEN_TYPE_KEY),
+		RefreshToken: oauth2Section.GetAttribute(global.OAUTH2_REFRESH_TOKEN_KEY),
+	}
+
+	if expiry := oauth2Section.GetAttribute(global.OAUTH2_EXPIRY_KEY); expiry != "" {
+		parsedExpiry, parseErr := time.Parse(time.RFC3339, expiry)
+
+		if parseErr != nil {
+			thisCredential.Factory.Log.Error().Err(parseErr).Msg(ERR_OAUTH2_INVALID_EXPIRY)
+			return nil, errors.New(ERR_OAUTH2_INVALID_EXPIRY)
+		}
+
+		myToken.Expiry = parsedExpiry
+	}
+
+	return myToken, nil
+}
+
+/*
+SetOAuth2Token stores myToken in the oauth2 section of the Credential's Profile, for example after the authorization
+code has been exchanged for the first token. Blank values remove the matching attribute. The Credential still has to
+be saved.
+*/
+func (thisCredential *Credential) SetOAuth2Token(myToken *Token) error {
+	if !thisCredential.Initialized {
+		return errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	var expiry string
+
+	if !myToken.Expiry.IsZero() {
+		expiry = myToken.Expiry.UTC().Format(time.RFC3339)
+	}
+
+	oauth2Section := thisCredential.Section(global.OAUTH2_SECTION_NAME)
+	values := map[string]string{
+		global.OAUTH2_ACCESS_TOKEN_KEY:  myToken.AccessToken,
+		global.OAUTH2_TOKEN_TYPE_KEY:    myToken.TokenType,
+		global.OAUTH2_REFRESH_TOKEN_KEY: myToken.RefreshToken,
+		global.OAUTH2_EXPIRY_KEY:        expiry,
+	}
+
+	for key, value := range values {
+		var attributeErr error
+
+		if value != "" {
+			attributeErr = oauth2Section.SetAttribute(key, value)
+		} else if oauth2Section.GetAttribute(key) != "" {
+			attributeErr = oauth2Section.DeleteAttribute(key)
+		}
+
+		if attributeErr != nil {
+			return attributeErr
+		}
+	}
+
+	return nil
+}
+
+/*
+NewTokenSource creates a TokenSource for myCredential, using the default TokenSourceOptions. See
+NewTokenSourceWithOptions.
+*/
+func NewTokenSource(myCredential *Credential) (*TokenSource, error) {
+	return NewTokenSourceWithOptions(myCredential, TokenSourceOptions{})
+}
+
+/*
+NewTokenSourceWithOptions creates a TokenSource that keeps the OAuth2 token of myCredential in the oauth2 section of
+its Profile. The Credential's Username and Password are the client ID and client secret. When the access token has
+expired, the refresh token is exchanged for a new one at the token endpoint and the new token is saved with
+Credential.Save, so that it survives a restart:
+
+	mySource, sourceErr := credential.NewTokenSourceWithOptions(myCredential, credential.TokenSourceOptions{
+		TokenURL: "https://auth.example.com/oauth/token",
+	})
+	client := &http.Client{Transport: mySource.Transport(nil)}
+
+The first token has to be stored with SetOAuth2Token, as obtaining it usually needs the user.
+*/
+func NewTokenSourceWithOptions(myCredential *Credential, options TokenSourceOptions) (*TokenSource, error) {
+	if myCredential == nil || !myCredential.Initialized {
+		return nil, errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	if options.TokenURL == "" {
+		options.TokenURL = myCredential.Section(global.OAUTH2_SECTION_NAME).GetAttribute(global.OAUTH2_TOKEN_URL_KEY)
+	}
+
+	if options.TokenURL == "" {
+		myCredential.Factory.Log.Error().Msg(ERR_OAUTH2_TOKEN_URL_NOT_SET)
+		return nil, errors.New(ERR_OAUTH2_TOKEN_URL_NOT_SET)
+	}
+
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+
+	if options.ExpiryDelta <= 0 {
+		options.ExpiryDelta = defaultOAuth2ExpiryDelta
+	}
+
+	return &TokenSource{credential: myCredential, options: options}, nil
+}
+
+/*
+Token returns the stored Token if it is still valid, otherwise it is refreshed first (see Refresh). Before refreshing,
+changes saved by other processes are merged in (see MergeFromDisk), so a token that another process has already
+refreshed is used rather than spending the same refresh token again. If a refreshed Token could not be saved, the save
+is tried again by every call, but a failure is only logged, so the Token is still used while it is valid.
+*/
+func (thisSource *TokenSource) Token() (*Token, error) {
+	thisSource.mutex.Lock()
+	defer thisSource.mutex.Unlock()
+
+	if thisSource.unsaved {
+		if saveErr := thisSource.save(); saveErr != nil {
+			thisSource.credential.Factory.Log.Error().Err(saveErr).Str("profile", thisSource.credential.Profile.Name).Msg(ERR_OAUTH2_TOKEN_NOT_SAVED)
+		}
+	}
+
+	myToken, tokenErr := thisSource.credential.GetOAuth2Token()
+
+	if tokenErr != nil || thisSource.isValid(myToken) {
+		return myToken, tokenErr
+	}
+
+	if mergeErr := thisSource.mergeFromDisk(); mergeErr != nil {
+		return nil, mergeErr
+	}
+
+	myToken, tokenErr = thisSource.credential.GetOAuth2Token()
+
+	if tokenErr != nil || thisSource.isValid(myToken) {
+		return myToken, tokenErr
+	}
+
+	return thisSource.refresh(myToken)
+}
+
+/*
+Refresh merges in changes saved by other processes (see MergeFromDisk), then exchanges the refresh token for a new
+Token, even if the stored one is still valid, and saves it with Credential.Save. If the token endpoint does not return a
+new refresh token, the old one is kept. If the profile was changed on disk in the meantime, the changes are merged and
+the save is tried again. If the new Token still cannot be saved, the error is returned and the save is tried again by
+the next call to Token, as the token endpoint may have revoked the old refresh token.
+*/
+func (thisSource *TokenSource) Refresh() (*Token, error) {
+	thisSource.mutex.Lock()
+	defer thisSource.mutex.Unlock()
+
+	if mergeErr := thisSource.mergeFromDisk(); mergeErr != nil {
+		return nil, mergeErr
+	}
+
+	myToken, tokenErr := thisSource.credential.GetOAuth2Token()
+
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+
+	return thisSource.refresh(myToken)
+}
+
+func (thisSource *TokenSource) isValid(myToken *Token) bool {
+	return myToken.AccessToken != "" &&
+		(myToken.Expiry.IsZero() || time.Now().Add(thisSource.options.ExpiryDelta).Before(myToken.Expiry))
+}
+
+// mergeFromDisk merges in what is saved for the Credential's profile, unless the Credential has never been saved.
+func (thisSource *TokenSource) mergeFromDisk() error {
+	if thisSource.credential.saved == nil {
+		return nil
+	}
+
+	conflicts, mergeErr := thisSource.credential.MergeFromDisk()
+
+	if len(conflicts) > 0 {
+		thisSource.credential.Factory.Log.Debug().Int("conflicts", len(conflicts)).Msg("Kept local values that were also changed on disk.")
+	}
+
+	return mergeErr
+}
+
+// save saves the Credential, merging in changes made on disk and trying again if they conflict with it.
+func (thisSource *TokenSource) save() error {
+	saveErr := thisSource.credential.Save()
+
+	if saveErr != nil && saveErr.Error() == ERR_SAVE_CONFLICT {
+		if mergeErr := thisSource.mergeFromDisk(); mergeErr != nil {
+			return mergeErr
+		}
+
+		saveErr = thisSource.credential.Save()
+	}
+
+	thisSource.unsaved = saveErr != nil
+	return saveErr
+}
+
+func (thisSource *TokenSource) refresh(oldToken *Token) (*Token, error) {
+	log := thisSource.credential.Factory.Log
+
+	if oldToken.RefreshToken == "" {
+		log.Error().Msg(ERR_OAUTH2_NO_REFRESH_TOKEN)
+		return nil, errors.New(ERR_OAUTH2_NO_REFRESH_TOKEN)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", oldToken.RefreshToken)
+
+	if len(thisSource.options.Scopes) > 0 {
+		form.Set("scope", strings.Join(thisSource.options.Scopes, " "))
+	}
+
+	if thisSource.options.ClientCredentialsInBody {
+		form.Set("client_id", thisSource.credential.Username)
+		form.Set("client_secret", thisSource.credential.Password)
+	}
+
+	request, requestErr := http.NewRequest(http.MethodPost, thisSource.options.TokenURL, strings.NewReader(form.Encode()))
+
+	if requestErr != nil {
+		return nil, requestErr
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	if !thisSource.options.ClientCredentialsInBody {
+		// RFC 6749 section 2.3.1 requires the client ID and secret to be form encoded before basic authentication.
+		request.SetBasicAuth(url.QueryEscape(thisSource.credential.Username), url.QueryEscape(thisSource.credential.Password))
+	}
+
+	response, responseErr := thisSource.options.Client.Do(request)
+
+	if responseErr != nil {
+		return nil, responseErr
+	}
+
+	defer response.Body.Close()
+	var body tokenResponse
+	decodeErr := json.NewDecoder(response.Body).Decode(&body)
+
+	if decodeErr != nil || response.StatusCode < 200 || response.StatusCode > 299 || body.AccessToken == "" {
+		log.Error().Int("status", response.StatusCode).Str("error", body.Error).Str("description", body.ErrorDescription).Msg(ERR_OAUTH2_REFRESH_FAILED)
+		return nil, errors.New(ERR_OAUTH2_REFRESH_FAILED)
+	}
+
+	newToken := &Token{AccessToken: body.AccessToken, TokenType: body.TokenType, RefreshToken: body.RefreshToken}
+
+	if newToken.RefreshToken == "" {
+		newToken.RefreshToken = oldToken.RefreshToken
+	}
+
+	if expiresIn, parseErr := body.ExpiresIn.Int64(); parseErr == nil && expiresIn > 0 {
+		newToken.Expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+
+	if setErr := thisSource.credential.SetOAuth2Token(newToken); setErr != nil {
+		return nil, setErr
+	}
+
+	log.Info().Str("profile", thisSource.credential.Profile.Name).Msg("Refreshed OAuth2 token.")
+
+	if saveErr := thisSource.save(); saveErr != nil {
+		return nil, saveErr
+	}
+
+	return newToken, nil
+}
+
+/*
+Transport returns an http.RoundTripper that sends every request through base (http.DefaultTransport if nil) with the
+access token from Token in the Authorization header.
+*/
+func (thisSource *TokenSource) Transport(base http.RoundTripper) http.RoundTripper {
+	return &tokenTransport{source: thisSource, base: base}
+}
+
+func (thisTransport *tokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	myToken, tokenErr := thisTransport.source.Token()
+
+	if tokenErr != nil {
+		if request.Body != nil {
+			request.Body.Close()
+		}
+
+		return nil, tokenErr
+	}
+
+	tokenType := myToken.TokenType
+
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	return sendWithHeader(thisTransport.base, request, "Authorization", tokenType+" "+myToken.AccessToken)
+}
@@ -0,0 +1,243 @@
+package credential
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+/*
+PromptAttribute is an attribute that must be asked for when a profile is created by PromptWithOptions.
+
+Section: the section the attribute is stored in, or blank for an attribute without a section.
+
+Key: the key the attribute is stored as, which must match the same regex as SetAttribute.
+
+Label: the text shown to the user when asking for the attribute. Defaults to Key.
+
+Secret: read the value without echoing it, in the same way as the password.
+*/
+type PromptAttribute struct {
+	Section string
+	Key     string
+	Label   string
+	Secret  bool
+}
+
+/*
+PromptOptions changes the behaviour of PromptWithOptions and LoadOrPromptWithOptions.
+
+Input: where answers are read from. Defaults to os.Stdin. If Input is an *os.File it must be a terminal, so that the
+password can be read without echo, otherwise ERR_PROMPT_NOT_A_TERMINAL is returned. Terminals are supported on Linux,
+macOS, the BSDs and Windows; elsewhere ERR_PROMPT_UNSUPPORTED_PLATFORM is returned for an *os.File. Any other io.Reader
+is read a line at a time without hiding anything, which is intended for scripting and tests.
+
+Output: where the questions are written. Defaults to os.Stderr, so that the standard output of a command is left clean.
+
+Attributes: the attributes that must be given, in the order they are asked for, after the username and password.
+*/
+type PromptOptions struct {
+	Input      io.Reader
+	Output     io.Writer
+	Attributes []PromptAttribute
+}
+
+// prompter asks questions on Output and reads the answers from Input.
+type prompter struct {
+	reader   *bufio.Reader
+	output   io.Writer
+	terminal *os.File
+}
+
+/*
+Prompt asks the user for the username and password of profileName and saves them as a new profile, using the default
+PromptOptions. See PromptWithOptions.
+*/
+func Prompt(profileName string, sourceFactory *factory.Factory) (*Credential, error) {
+	return PromptWithOptions(profileName, sourceFactory, PromptOptions{})
+}
+
+/*
+PromptWithOptions asks the user for the username and password of profileName, followed by each of options.Attributes,
+then saves the result with Save and returns it. The questions use the labels returned by Factory.GetAlternates, so an
+application that stores an api_key rather than a password asks for an api_key. The password and any secret attributes
+are read from the terminal with echo turned off. Blank answers are asked for again.
+
+If standard input is not a terminal, for example when running under a scheduler or in a pipeline, ERR_PROMPT_NOT_A_TERMINAL
+is returned rather than waiting for input that will never come, and nothing is saved. If the input ends before every
+question is answered, ERR_PROMPT_INPUT_ENDED is returned.
+*/
+func PromptWithOptions(profileName string, sourceFactory *factory.Factory, options PromptOptions) (*Credential, error) {
+	if sourceFactory == nil || !sourceFactory.Initialized {
+		return nil, errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	myPrompter, prompterErr := newPrompter(options)
+
+	if prompterErr != nil {
+		sourceFactory.Log.Error().Err(prompterErr).Msg("Unable to prompt for credentials.")
+		return nil, prompterErr
+	}
+
+	usernameLabel, passwordLabel := sourceFactory.GetAlternates()
+	sourceFactory.Log.Trace().Str("profile", profileName).Msg("Prompting for credentials.")
+	username, askErr := myPrompter.ask(usernameLabel, false)
+
+	if askErr != nil {
+		return nil, askErr
+	}
+
+	password, askErr := myPrompter.ask(passwordLabel, true)
+
+	if askErr != nil {
+		return nil, askErr
+	}
+
+	myCredential, credErr := NewProfile(profileName, sourceFactory, username, password)
+
+	if credErr != nil {
+		return nil, credErr
+	}
+
+	for _, attribute := range options.Attributes {
+		label := attribute.Label
+
+		if label == "" {
+			label = attribute.Key
+		}
+
+		value, askErr := myPrompter.ask(label, attribute.Secret)
+
+		if askErr != nil {
+			return nil, askErr
+		}
+
+		targetCredential := myCredential
+
+		if attribute.Section != "" {
+			targetCredential = myCredential.Section(attribute.Section)
+		}
+
+		if setErr := targetCredential.SetAttribute(attribute.Key, value); setErr != nil {
+			return nil, setErr
+		}
+	}
+
+	if saveErr := myCredential.Save(); saveErr != nil {
+		return nil, saveErr
+	}
+
+	return myCredential, nil
+}
+
+/*
+LoadOrPrompt loads profileName if it is stored, or prompts for it with the default PromptOptions if it is not. See
+LoadOrPromptWithOptions.
+*/
+func LoadOrPrompt(profileName string, sourceFactory *factory.Factory) (*Credential, error) {
+	return LoadOrPromptWithOptions(profileName, sourceFactory, PromptOptions{})
+}
+
+/*
+LoadOrPromptWithOptions loads profileName with LoadFromProfile if it is stored, and otherwise asks the user for it with
+PromptWithOptions, which saves it for next time. Errors loading a profile that is stored are returned as is, rather than
+prompting over the top of it.
+*/
+func LoadOrPromptWithOptions(profileName string, sourceFactory *factory.Factory, options PromptOptions) (*Credential, error) {
+	if sourceFactory == nil || !sourceFactory.Initialized {
+		return nil, errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	profiles, listErr := serializer.ListProfiles(sourceFactory)
+
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	for _, storedProfile := range profiles {
+		if storedProfile == profileName {
+			return LoadFromProfile(profileName, sourceFactory)
+		}
+	}
+
+	return PromptWithOptions(profileName, sourceFactory, options)
+}
+
+func newPrompter(options PromptOptions) (*prompter, error) {
+	input, output := options.Input, options.Output
+
+	if input == nil {
+		input = os.Stdin
+	}
+
+	if output == nil {
+		output = os.Stderr
+	}
+
+	myPrompter := &prompter{
+		reader: bufio.NewReader(input),
+		output: output,
+	}
+
+	if inputFile, isFile := input.(*os.File); isFile {
+		if !terminalSupported {
+			return nil, errors.New(ERR_PROMPT_UNSUPPORTED_PLATFORM)
+		}
+
+		if !isTerminal(inputFile) {
+			return nil, errors.New(ERR_PROMPT_NOT_A_TERMINAL)
+		}
+
+		myPrompter.terminal = inputFile
+	}
+
+	return myPrompter, nil
+}
+
+// ask writes label as a question and returns the first non-blank answer, hiding it from the terminal if secret is set.
+func (thisPrompter *prompter) ask(label string, secret bool) (string, error) {
+	for {
+		if _, writeErr := fmt.Fprintf(thisPrompter.output, "%s: ", label); writeErr != nil {
+			return "", writeErr
+		}
+
+		answer, readErr := thisPrompter.readLine(secret)
+
+		if readErr != nil {
+			return "", readErr
+		}
+
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
+
+func (thisPrompter *prompter) readLine(secret bool) (string, error) {
+	if secret && thisPrompter.terminal != nil {
+		restore, echoErr := disableEcho(thisPrompter.terminal)
+
+		if echoErr != nil {
+			return "", echoErr
+		}
+
+		defer fmt.Fprintln(thisPrompter.output)
+		defer restore()
+	}
+
+	line, readErr := thisPrompter.reader.ReadString('\n')
+
+	if readErr == io.EOF && line == "" {
+		return "", errors.New(ERR_PROMPT_INPUT_ENDED)
+	} else if readErr != nil && readErr != io.EOF {
+		return "", readErr
+	}
+
+	return strings.TrimSpace(line), nil
+}
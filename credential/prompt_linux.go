@@ -0,0 +1,9 @@
+//go:build linux
+// +build linux
+
+package credential
+
+import "syscall"
+
+const ioctlGetTermios = syscall.TCGETS
+const ioctlSetTermios = syscall.TCSETS
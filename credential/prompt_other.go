@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd,!windows
+
+package credential
+
+import (
+	"errors"
+	"os"
+)
+
+// terminalSupported is false as terminals are only handled with termios or the Windows console API.
+const terminalSupported = false
+
+func isTerminal(file *os.File) bool {
+	return false
+}
+
+func disableEcho(terminal *os.File) (func(), error) {
+	return nil, errors.New(ERR_PROMPT_UNSUPPORTED_PLATFORM)
+}
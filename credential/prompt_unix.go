@@ -0,0 +1,61 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package credential
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const terminalSupported = true
+
+func getTermios(terminal *os.File) (syscall.Termios, error) {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, terminal.Fd(), ioctlGetTermios, uintptr(unsafe.Pointer(&termios)))
+
+	if errno != 0 {
+		return termios, errno
+	}
+
+	return termios, nil
+}
+
+func setTermios(terminal *os.File, termios syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, terminal.Fd(), ioctlSetTermios, uintptr(unsafe.Pointer(&termios)))
+
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// isTerminal reports whether file is a terminal, which is the case when its terminal attributes can be read.
+func isTerminal(file *os.File) bool {
+	_, termiosErr := getTermios(file)
+	return termiosErr == nil
+}
+
+/*
+disableEcho stops terminal from echoing what is typed, while still reading a line at a time, and returns a function that
+puts the terminal back the way it was.
+*/
+func disableEcho(terminal *os.File) (func(), error) {
+	original, termiosErr := getTermios(terminal)
+
+	if termiosErr != nil {
+		return nil, termiosErr
+	}
+
+	hidden := original
+	hidden.Lflag &^= syscall.ECHO
+	hidden.Lflag |= syscall.ICANON | syscall.ISIG
+
+	if setErr := setTermios(terminal, hidden); setErr != nil {
+		return nil, setErr
+	}
+
+	return func() { setTermios(terminal, original) }, nil
+}
@@ -0,0 +1,53 @@
+//go:build windows
+// +build windows
+
+package credential
+
+import (
+	"os"
+	"syscall"
+)
+
+const terminalSupported = true
+
+const enableProcessedInput = 0x0001
+const enableLineInput = 0x0002
+const enableEchoInput = 0x0004
+
+// syscall has GetConsoleMode but not SetConsoleMode, so it is loaded from kernel32.dll.
+var setConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+func setMode(console syscall.Handle, mode uint32) error {
+	if result, _, callErr := setConsoleMode.Call(uintptr(console), uintptr(mode)); result == 0 {
+		return callErr
+	}
+
+	return nil
+}
+
+// isTerminal reports whether file is a console, which is the case when its console mode can be read.
+func isTerminal(file *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(file.Fd()), &mode) == nil
+}
+
+/*
+disableEcho stops the console from echoing what is typed, while still reading a line at a time, and returns a function
+that puts the console back the way it was.
+*/
+func disableEcho(terminal *os.File) (func(), error) {
+	console := syscall.Handle(terminal.Fd())
+	var original uint32
+
+	if modeErr := syscall.GetConsoleMode(console, &original); modeErr != nil {
+		return nil, modeErr
+	}
+
+	hidden := original&^enableEchoInput | enableProcessedInput | enableLineInput
+
+	if setErr := setMode(console, hidden); setErr != nil {
+		return nil, setErr
+	}
+
+	return func() { setMode(console, original) }, nil
+}
@@ -0,0 +1,178 @@
+package credential
+
+import (
+	"errors"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+/*
+Source is somewhere Resolve can find a Credential. Name identifies the source, and is one of the global.SOURCE_* values
+for the sources provided by this package. Load returns the Credential for profileName, or ERR_SOURCE_INCOMPLETE if the
+source does not have both a username and password for it, in which case Resolve moves on to the next source. Any other
+error stops Resolve.
+*/
+type Source interface {
+	Name() string
+	Load(sourceFactory *factory.Factory, profileName string) (*Credential, error)
+}
+
+type explicitSource struct {
+	username string
+	password string
+}
+
+type environmentSource struct{}
+
+type fileSource struct{}
+
+type promptSource struct {
+	options PromptOptions
+}
+
+/*
+Resolve tries each of sources in order, and returns the first complete Credential for profileName along with the Source
+that it came from. If no sources are given, the environment, the files and then a prompt are tried. If none of the
+sources has a complete Credential, ERR_RESOLVE_NOT_FOUND is returned.
+
+	myCredential, mySource, resolveErr := credential.Resolve(myFactory, "default",
+		credential.Explicit(*usernameFlag, *passwordFlag),
+		credential.Environment(),
+		credential.File(),
+		credential.PromptSource(credential.PromptOptions{}))
+
+Errors other than ERR_SOURCE_INCOMPLETE are returned straight away rather than trying the next source, so that a broken
+credentials file is reported instead of being prompted over.
+*/
+func Resolve(sourceFactory *factory.Factory, profileName string, sources ...Source) (*Credential, Source, error) {
+	if sourceFactory == nil || !sourceFactory.Initialized {
+		return nil, nil, errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	if len(sources) == 0 {
+		sources = []Source{Environment(), File(), PromptSource(PromptOptions{})}
+	}
+
+	for _, mySource := range sources {
+		sourceFactory.Log.Trace().Str("profile", profileName).Str("source", mySource.Name()).Msg("Resolving credential.")
+		myCredential, loadErr := mySource.Load(sourceFactory, profileName)
+
+		if loadErr == nil {
+			sourceFactory.Log.Debug().Str("profile", profileName).Str("source", mySource.Name()).Msg("Credential resolved.")
+			return myCredential, mySource, nil
+		}
+
+		if loadErr.Error() != ERR_SOURCE_INCOMPLETE {
+			sourceFactory.Log.Error().Err(loadErr).Str("source", mySource.Name()).Msg("Unable to resolve credential.")
+			return nil, nil, loadErr
+		}
+	}
+
+	sourceFactory.Log.Error().Str("profile", profileName).Msg(ERR_RESOLVE_NOT_FOUND)
+	return nil, nil, errors.New(ERR_RESOLVE_NOT_FOUND)
+}
+
+/*
+Explicit returns a Source for a username and password that are already known, such as those passed as command-line
+flags. It is incomplete unless both are set. The Credential is not saved.
+*/
+func Explicit(username string, password string) Source {
+	return explicitSource{username: username, password: password}
+}
+
+func (thisSource explicitSource) Name() string {
+	return global.SOURCE_EXPLICIT
+}
+
+func (thisSource explicitSource) Load(sourceFactory *factory.Factory, profileName string) (*Credential, error) {
+	if thisSource.username == "" || thisSource.password == "" {
+		return nil, errors.New(ERR_SOURCE_INCOMPLETE)
+	}
+
+	return NewProfile(profileName, sourceFactory, thisSource.username, thisSource.password)
+}
+
+/*
+Environment returns a Source that loads the profile from the variables used by the env output type, whatever the
+output type of the Factory is. It is incomplete unless both the username and password variables are set. The returned
+Credential uses a copy of the Factory with the env output type, so saving it updates the environment.
+*/
+func Environment() Source {
+	return environmentSource{}
+}
+
+func (thisSource environmentSource) Name() string {
+	return global.SOURCE_ENVIRONMENT
+}
+
+func (thisSource environmentSource) Load(sourceFactory *factory.Factory, profileName string) (*Credential, error) {
+	envFactory := withOutputType(sourceFactory, global.OUTPUT_TYPE_ENV)
+	myCredential, loadErr := LoadFromProfile(profileName, envFactory)
+
+	if loadErr != nil && (loadErr.Error() == serializer.ERR_REQUIRED_VARIABLE_USERNAME_NOT_FOUND ||
+		loadErr.Error() == serializer.ERR_REQUIRED_VARIABLE_PASSWORD_NOT_FOUND) {
+		return nil, errors.New(ERR_SOURCE_INCOMPLETE)
+	}
+
+	return myCredential, loadErr
+}
+
+/*
+File returns a Source that loads the profile from the credentials and config files. It is incomplete if the profile is
+not stored. If the Factory uses the env output type, the returned Credential uses a copy of it with the ini output type
+(the format of the files themselves is always detected, see serializer.Deserialize).
+*/
+func File() Source {
+	return fileSource{}
+}
+
+func (thisSource fileSource) Name() string {
+	return global.SOURCE_FILE
+}
+
+func (thisSource fileSource) Load(sourceFactory *factory.Factory, profileName string) (*Credential, error) {
+	fileFactory := sourceFactory
+
+	if sourceFactory.OutputType == global.OUTPUT_TYPE_ENV {
+		fileFactory = withOutputType(sourceFactory, global.OUTPUT_TYPE_INI)
+	}
+
+	profiles, listErr := serializer.ListProfiles(fileFactory)
+
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	for _, storedProfile := range profiles {
+		if storedProfile == profileName {
+			return LoadFromProfile(profileName, fileFactory)
+		}
+	}
+
+	return nil, errors.New(ERR_SOURCE_INCOMPLETE)
+}
+
+/*
+PromptSource returns a Source that asks the user for the profile and saves it, see PromptWithOptions. As ERR_PROMPT_NOT_A_TERMINAL
+is returned when standard input is not a terminal, it is normally the last source.
+*/
+func PromptSource(options PromptOptions) Source {
+	return promptSource{options: options}
+}
+
+func (thisSource promptSource) Name() string {
+	return global.SOURCE_PROMPT
+}
+
+func (thisSource promptSource) Load(sourceFactory *factory.Factory, profileName string) (*Credential, error) {
+	return PromptWithOptions(profileName, sourceFactory, thisSource.options)
+}
+
+// withOutputType returns a copy of sourceFactory that uses outputType, leaving sourceFactory as it is.
+func withOutputType(sourceFactory *factory.Factory, outputType string) *factory.Factory {
+	copiedFactory := *sourceFactory
+	copiedFactory.OutputType = outputType
+	return &copiedFactory
+}
@@ -18,6 +18,12 @@ func Load(sourceFactory *factory.Factory) (*Credential, error) {
 /*
 Save is responsible for saving the credential at ~/.application_name/credentials in the specified output format
 that has been set on the Credentials' Factory object.
+
+Nothing is written if the Credential has not changed since it was loaded or last saved (see IsDirty). If only the
+Profile's attributes have changed, the credentials file is left alone and only the Profile's config file is written.
+If the stored Credential was changed by something else since it was loaded, ERR_SAVE_CONFLICT is returned rather than
+overwriting that change; see MergeFromDisk. If Factory.HistorySize is set, the saved profile is also added to the
+history (see ListVersions); a failure to do so is logged, as the Credential itself has already been saved.
 */
 func (thisCredential *Credential) Save() error {
 	if !thisCredential.Factory.Initialized || !thisCredential.Initialized {
@@ -28,8 +34,58 @@ func (thisCredential *Credential) Save() error {
 		return errors.New(profile.ERR_PROFILE_NOT_INITIALIZED)
 	}
 
+	if !thisCredential.IsDirty() {
+		thisCredential.Factory.Log.Debug().Msg("Credential has not changed, nothing to save.")
+		return nil
+	}
+
+	conflictErr := thisCredential.checkForConflict()
+
+	if conflictErr != nil {
+		return conflictErr
+	}
+
+	previousVersion := thisCredential.savedVersion()
+	mySerializer := serializer.New(thisCredential.Factory, thisCredential.Profile.Name)
+	var saveErr error
+
+	if thisCredential.isCredentialDirty() {
+		saveErr = mySerializer.Serialize(thisCredential.Serialize())
+	} else {
+		thisCredential.Factory.Log.Debug().Msg("Username and password have not changed, only saving the profile.")
+		saveErr = mySerializer.SerializeProfile(thisCredential.Profile.GetAllAttributes())
+	}
+
+	if saveErr != nil {
+		return saveErr
+	}
+
+	thisCredential.markClean()
+
+	// The Credential has been saved at this point, so failing to add it to the history does not fail the save.
+	if historyErr := thisCredential.recordHistory(previousVersion); historyErr != nil {
+		thisCredential.Factory.Log.Error().Err(historyErr).Str("profile", thisCredential.Profile.Name).Msg(ERR_HISTORY_NOT_RECORDED)
+	}
+
+	return nil
+}
+
+/*
+ClearEnv removes every environment variable belonging to the Credential's Profile, using the same variable names as the
+env output type, from both the Factory's environment and the environment of the current process. The Credential itself
+and any files it has been saved to are not changed.
+*/
+func (thisCredential *Credential) ClearEnv() error {
+	if !thisCredential.Factory.Initialized || !thisCredential.Initialized {
+		return errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	if !thisCredential.Profile.Initialized {
+		return errors.New(profile.ERR_PROFILE_NOT_INITIALIZED)
+	}
+
 	mySerializer := serializer.New(thisCredential.Factory, thisCredential.Profile.Name)
-	return mySerializer.Serialize(thisCredential.Serialize())
+	return mySerializer.ClearEnv()
 }
 
 /*
@@ -48,6 +104,99 @@ func LoadFromProfile(profileName string, sourceFactory *factory.Factory) (*Crede
 		return nil, deErr
 	}
 
+	myCredential, credErr := Deserialize(sourceFactory, profileName, username, password, attributes)
+
+	if credErr != nil {
+		return nil, credErr
+	}
+
+	myCredential.markClean()
+	return myCredential, nil
+}
+
+/*
+DeleteProfile removes a stored profile, using the output type of sourceFactory, without affecting any other profile.
+See serializer.Delete.
+*/
+func DeleteProfile(profileName string, sourceFactory *factory.Factory) error {
+	if !sourceFactory.Initialized {
+		return errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	if _, profileErr := profile.New(profileName, sourceFactory); profileErr != nil {
+		return profileErr
+	}
+
+	return serializer.New(sourceFactory, profileName).Delete()
+}
+
+/*
+LoadMatchingProfile is for stores where different values, such as two server URLs, can give the same profile name. It
+goes through profileNames in order and returns the first stored profile that isMatch accepts, along with its name. If
+none is accepted, the Credential is nil and the name is the first of profileNames that is not stored yet, so that a new
+profile can be created without overwriting one that belongs to another value. If every name is taken,
+ERR_PROFILE_NAMES_TAKEN is returned.
+*/
+func LoadMatchingProfile(sourceFactory *factory.Factory, profileNames []string, isMatch func(*Credential) bool) (*Credential, string, error) {
+	if !sourceFactory.Initialized {
+		return nil, "", errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	profiles, listErr := serializer.ListProfiles(sourceFactory)
+
+	if listErr != nil {
+		return nil, "", listErr
+	}
+
+	var freeName string
+
+	for _, profileName := range profileNames {
+		if !global.Contains(profiles, profileName) {
+			if freeName == "" {
+				freeName = profileName
+			}
+
+			continue
+		}
+
+		myCredential, loadErr := LoadFromProfile(profileName, sourceFactory)
+
+		if loadErr != nil {
+			return nil, "", loadErr
+		}
+
+		if isMatch(myCredential) {
+			return myCredential, profileName, nil
+		}
+
+		sourceFactory.Log.Debug().Str("profile", profileName).Msg("Profile name is taken by another value.")
+	}
+
+	if freeName == "" {
+		sourceFactory.Log.Error().Strs("profiles", profileNames).Msg(ERR_PROFILE_NAMES_TAKEN)
+		return nil, "", errors.New(ERR_PROFILE_NAMES_TAKEN)
+	}
+
+	return nil, freeName, nil
+}
+
+/*
+LoadFromDotEnv creates a Credential and Profile from a dotenv file that uses the same variable names as the env output
+type, such as one written by Export with global.EXPORT_TYPE_DOTENV. The file is read directly, so nothing is added to
+the environment of the current process.
+*/
+func LoadFromDotEnv(profileName string, sourceFactory *factory.Factory, fileName string) (*Credential, error) {
+	if !sourceFactory.Initialized {
+		return nil, errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	mySerializer := serializer.New(sourceFactory, profileName)
+	username, password, attributes, deErr := mySerializer.FromDotEnv(fileName)
+
+	if deErr != nil {
+		return nil, deErr
+	}
+
 	return Deserialize(sourceFactory, profileName, username, password, attributes)
 }
 
@@ -92,3 +241,31 @@ func Deserialize(sourceFactory *factory.Factory, profileName string, username st
 	myCredential.Profile = myProfile
 	return myCredential, nil
 }
+
+/*
+Export renders the Credential and its Profile as a dotenv file, a POSIX export script or a fish script, depending on
+exportType (global.EXPORT_TYPE_DOTENV, global.EXPORT_TYPE_POSIX or global.EXPORT_TYPE_FISH). The variable names match
+those used when the Factory's output type is env.
+*/
+func (thisCredential *Credential) Export(exportType string) (string, error) {
+	if !thisCredential.Initialized || !thisCredential.Profile.Initialized {
+		return "", errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	mySerializer := serializer.New(thisCredential.Factory, thisCredential.Profile.Name)
+	username, password, attributes := thisCredential.Serialize()
+	return mySerializer.Export(exportType, username, password, attributes)
+}
+
+/*
+ExportToFile writes the output of Export to fileName with 0600 permissions.
+*/
+func (thisCredential *Credential) ExportToFile(exportType string, fileName string) error {
+	if !thisCredential.Initialized || !thisCredential.Profile.Initialized {
+		return errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	mySerializer := serializer.New(thisCredential.Factory, thisCredential.Profile.Name)
+	username, password, attributes := thisCredential.Serialize()
+	return mySerializer.ExportToFile(exportType, fileName, username, password, attributes)
+}
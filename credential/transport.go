@@ -0,0 +1,198 @@
+package credential
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+/*
+AuthScheme says how AuthTransport authenticates requests. Use BasicAuth, BearerAuth or ApiKeyAuth to create one.
+
+Type: one of global.AUTH_SCHEME_BASIC, global.AUTH_SCHEME_BEARER or global.AUTH_SCHEME_API_KEY.
+
+Section and Attribute: the attribute holding the token for bearer and api_key. If Attribute is blank, the Password is
+used.
+
+Header: the header the api_key is sent in. Defaults to global.DEFAULT_API_KEY_HEADER.
+*/
+type AuthScheme struct {
+	Type      string
+	Section   string
+	Attribute string
+	Header    string
+}
+
+/*
+AuthTransport is an http.RoundTripper that adds a Credential to every request, see Transport. It is safe to use from
+multiple goroutines, including while the Credential is being replaced.
+*/
+type AuthTransport struct {
+	Base        http.RoundTripper
+	scheme      AuthScheme
+	mutex       sync.RWMutex
+	credential  *Credential
+	headerName  string
+	headerValue string
+}
+
+// BasicAuth sends the Username and Password with HTTP basic authentication.
+func BasicAuth() AuthScheme {
+	return AuthScheme{Type: global.AUTH_SCHEME_BASIC}
+}
+
+// BearerAuth sends attribute (the Password if blank) as a bearer token in the Authorization header.
+func BearerAuth(attribute string) AuthScheme {
+	return AuthScheme{Type: global.AUTH_SCHEME_BEARER, Attribute: attribute}
+}
+
+// ApiKeyAuth sends attribute (the Password if blank) as it is in header (global.DEFAULT_API_KEY_HEADER if blank).
+func ApiKeyAuth(header string, attribute string) AuthScheme {
+	return AuthScheme{Type: global.AUTH_SCHEME_API_KEY, Header: header, Attribute: attribute}
+}
+
+/*
+Transport returns an http.RoundTripper that authenticates every request sent through base with myCredential, using
+scheme. If base is nil, http.DefaultTransport is used. Requests are copied before the header is added, as an
+http.RoundTripper must not change the request it is given.
+
+	myTransport, transportErr := credential.Transport(nil, myCredential, credential.BearerAuth("token"))
+	client := &http.Client{Transport: myTransport}
+
+The header is worked out when the Credential is given, so changes to it are only picked up by calling Update (for
+example from the channel returned by Watch, see Follow) or Reload. ERR_AUTH_VALUE_NOT_SET is returned if the Credential
+does not have the value the scheme needs.
+*/
+func Transport(base http.RoundTripper, myCredential *Credential, scheme AuthScheme) (*AuthTransport, error) {
+	if scheme.Type != global.AUTH_SCHEME_BASIC && scheme.Type != global.AUTH_SCHEME_BEARER && scheme.Type != global.AUTH_SCHEME_API_KEY {
+		return nil, errors.New(ERR_AUTH_SCHEME_INVALID)
+	}
+
+	if scheme.Type == global.AUTH_SCHEME_API_KEY && scheme.Header == "" {
+		scheme.Header = global.DEFAULT_API_KEY_HEADER
+	}
+
+	myTransport := &AuthTransport{Base: base, scheme: scheme}
+
+	if updateErr := myTransport.Update(myCredential); updateErr != nil {
+		return nil, updateErr
+	}
+
+	return myTransport, nil
+}
+
+/*
+Update replaces the Credential used to authenticate requests. Requests already being sent keep the old Credential. If
+myCredential does not have the value the scheme needs, ERR_AUTH_VALUE_NOT_SET is returned and the old Credential is
+kept.
+*/
+func (thisTransport *AuthTransport) Update(myCredential *Credential) error {
+	if myCredential == nil || !myCredential.Initialized {
+		return errors.New(ERR_NOT_INITIALIZED)
+	}
+
+	headerName, headerValue := "Authorization", ""
+	token := myCredential.Password
+
+	if thisTransport.scheme.Attribute != "" {
+		token = myCredential.Section(thisTransport.scheme.Section).GetAttribute(thisTransport.scheme.Attribute)
+
+		if thisTransport.scheme.Section == "" {
+			token = myCredential.GetAttribute(thisTransport.scheme.Attribute)
+		}
+	}
+
+	switch thisTransport.scheme.Type {
+	case global.AUTH_SCHEME_BASIC:
+		token = ""
+
+		if myCredential.Username != "" && myCredential.Password != "" {
+			token = myCredential.Username + ":" + myCredential.Password
+			headerValue = "Basic " + base64.StdEncoding.EncodeToString([]byte(token))
+		}
+	case global.AUTH_SCHEME_BEARER:
+		headerValue = "Bearer " + token
+	case global.AUTH_SCHEME_API_KEY:
+		headerName, headerValue = thisTransport.scheme.Header, token
+	}
+
+	if token == "" {
+		myCredential.Factory.Log.Error().Str("scheme", thisTransport.scheme.Type).Msg(ERR_AUTH_VALUE_NOT_SET)
+		return errors.New(ERR_AUTH_VALUE_NOT_SET)
+	}
+
+	thisTransport.mutex.Lock()
+	defer thisTransport.mutex.Unlock()
+	thisTransport.credential, thisTransport.headerName, thisTransport.headerValue = myCredential, headerName, headerValue
+	return nil
+}
+
+/*
+Reload loads the Credential's profile again (see LoadFromProfile) and uses it for subsequent requests, for when the
+application knows the stored credential has been refreshed.
+*/
+func (thisTransport *AuthTransport) Reload() error {
+	thisTransport.mutex.RLock()
+	current := thisTransport.credential
+	thisTransport.mutex.RUnlock()
+	reloaded, loadErr := LoadFromProfile(current.Profile.Name, current.Factory)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	return thisTransport.Update(reloaded)
+}
+
+/*
+Follow uses each Credential received from updates for subsequent requests, until updates is closed. It is intended for
+the channel returned by Watch, so that a credential changed on disk is used without restarting:
+
+	updates, watchErr := credential.Watch(ctx, myFactory, "default")
+	myTransport.Follow(updates)
+
+Credentials that do not have the value the scheme needs are skipped.
+*/
+func (thisTransport *AuthTransport) Follow(updates <-chan *Credential) {
+	go func() {
+		for myCredential := range updates {
+			if updateErr := thisTransport.Update(myCredential); updateErr != nil {
+				myCredential.Factory.Log.Warn().Err(updateErr).Msg("Skipping credential update for transport.")
+			}
+		}
+	}()
+}
+
+// GetCredential returns the Credential currently used to authenticate requests.
+func (thisTransport *AuthTransport) GetCredential() *Credential {
+	thisTransport.mutex.RLock()
+	defer thisTransport.mutex.RUnlock()
+	return thisTransport.credential
+}
+
+// RoundTrip sends a copy of request with the authentication header set, through Base.
+func (thisTransport *AuthTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	thisTransport.mutex.RLock()
+	headerName, headerValue := thisTransport.headerName, thisTransport.headerValue
+	thisTransport.mutex.RUnlock()
+
+	return sendWithHeader(thisTransport.Base, request, headerName, headerValue)
+}
+
+/*
+sendWithHeader sends a copy of request with headerName set to headerValue through base, or http.DefaultTransport if base
+is nil. The request is copied because an http.RoundTripper must not change the request it is given.
+*/
+func sendWithHeader(base http.RoundTripper, request *http.Request, headerName string, headerValue string) (*http.Response, error) {
+	authenticated := request.Clone(request.Context())
+	authenticated.Header.Set(headerName, headerValue)
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return base.RoundTrip(authenticated)
+}
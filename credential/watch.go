@@ -0,0 +1,187 @@
+package credential
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/profile"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+const defaultWatchDebounce = 100 * time.Millisecond
+const defaultWatchPollInterval = time.Second
+
+/*
+WatchOptions changes the behaviour of WatchWithOptions.
+
+Debounce: how long the files must be left alone before they are reloaded, so that a write in several parts is only
+loaded once it is complete. Defaults to 100ms.
+
+PollInterval: how often the files are checked when they are polled rather than watched. Defaults to 1s.
+
+Poll: always poll the files, even if the operating system can notify us of changes.
+*/
+type WatchOptions struct {
+	Debounce     time.Duration
+	PollInterval time.Duration
+	Poll         bool
+}
+
+/*
+Watch monitors the files of a stored profile and delivers a freshly loaded Credential on the returned channel whenever
+the profile changes, using the default WatchOptions. See WatchWithOptions.
+*/
+func Watch(ctx context.Context, sourceFactory *factory.Factory, profileName string) (<-chan *Credential, error) {
+	return WatchWithOptions(ctx, sourceFactory, profileName, WatchOptions{})
+}
+
+/*
+WatchWithOptions monitors the credentials file and the profile's config file, and delivers a freshly loaded Credential
+on the returned channel whenever the stored username, password or attributes of the profile change. On Linux the files
+are watched with inotify, elsewhere (or if inotify is unavailable) they are polled. Changes are debounced, and a change
+that leaves the profile as it was, such as saving another profile into the shared credentials file, is not delivered.
+If the files cannot be loaded, for example because they are half written, the change is skipped until the next one.
+
+Only the ini, json and aws output types can be watched. The channel is closed once ctx is done. The Factory is used to load
+the profile from another goroutine, so it should not be changed while it is being watched.
+*/
+func WatchWithOptions(ctx context.Context, sourceFactory *factory.Factory, profileName string, options WatchOptions) (<-chan *Credential, error) {
+	if sourceFactory == nil || !sourceFactory.Initialized {
+		return nil, errors.New(ERR_FACTORY_MUST_BE_INITIALIZED)
+	}
+
+	if sourceFactory.OutputType != global.OUTPUT_TYPE_INI && sourceFactory.OutputType != global.OUTPUT_TYPE_JSON &&
+		sourceFactory.OutputType != global.OUTPUT_TYPE_AWS {
+		sourceFactory.Log.Error().Str("output_type", sourceFactory.OutputType).Msg(ERR_WATCH_UNSUPPORTED_OUTPUT_TYPE)
+		return nil, errors.New(ERR_WATCH_UNSUPPORTED_OUTPUT_TYPE)
+	}
+
+	if _, profileErr := profile.New(profileName, sourceFactory); profileErr != nil {
+		return nil, profileErr
+	}
+
+	if options.Debounce <= 0 {
+		options.Debounce = defaultWatchDebounce
+	}
+
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultWatchPollInterval
+	}
+
+	fileNames, filesErr := serializer.New(sourceFactory, profileName).GetStorageFiles()
+
+	if filesErr != nil {
+		return nil, filesErr
+	}
+
+	events := make(chan struct{}, 1)
+	usePolling := options.Poll
+
+	if !usePolling {
+		if watchErr := watchFiles(ctx, fileNames, events); watchErr != nil {
+			sourceFactory.Log.Debug().Err(watchErr).Msg("Could not watch files, polling them instead.")
+			usePolling = true
+		}
+	}
+
+	if usePolling {
+		go pollFiles(ctx, fileNames, options.PollInterval, events)
+	}
+
+	lastCredential, _ := LoadFromProfile(profileName, sourceFactory)
+	credentials := make(chan *Credential)
+
+	go func() {
+		defer close(credentials)
+		var debounce <-chan time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-events:
+				debounce = time.After(options.Debounce)
+			case <-debounce:
+				debounce = nil
+				loadedCredential, loadErr := LoadFromProfile(profileName, sourceFactory)
+
+				if loadErr != nil {
+					sourceFactory.Log.Debug().Err(loadErr).Str("profile", profileName).Msg("Could not reload watched profile.")
+					continue
+				}
+
+				if isSameCredential(lastCredential, loadedCredential) {
+					continue
+				}
+
+				sourceFactory.Log.Info().Str("profile", profileName).Msg("Watched profile has changed.")
+				lastCredential = loadedCredential
+
+				select {
+				case credentials <- loadedCredential:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return credentials, nil
+}
+
+// notifyChange signals events without blocking, as one pending signal is enough to cause a reload.
+func notifyChange(events chan<- struct{}) {
+	select {
+	case events <- struct{}{}:
+	default:
+	}
+}
+
+// pollFiles signals events whenever the size or modification time of one of fileNames changes.
+func pollFiles(ctx context.Context, fileNames []string, interval time.Duration, events chan<- struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastStats := statFiles(fileNames)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			currentStats := statFiles(fileNames)
+
+			if currentStats != lastStats {
+				lastStats = currentStats
+				notifyChange(events)
+			}
+		}
+	}
+}
+
+func statFiles(fileNames []string) string {
+	var stats string
+
+	for _, fileName := range fileNames {
+		if fileInfo, statErr := os.Stat(fileName); statErr == nil {
+			stats += fileInfo.ModTime().String() + "/" + strconv.FormatInt(fileInfo.Size(), 10) + ";"
+		} else {
+			stats += "missing;"
+		}
+	}
+
+	return stats
+}
+
+func isSameCredential(first *Credential, second *Credential) bool {
+	if first == nil || second == nil {
+		return first == second
+	}
+
+	return first.Username == second.Username && first.Password == second.Password &&
+		len(profile.DiffAttributes(first.Profile.GetAllAttributes(), second.Profile.GetAllAttributes())) == 0
+}
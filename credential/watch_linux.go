@@ -0,0 +1,98 @@
+//go:build linux
+// +build linux
+
+package credential
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyWatchMask = syscall.IN_CLOSE_WRITE | syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+/*
+watchFiles uses inotify to signal events whenever one of fileNames changes. The directories are watched rather than the
+files themselves, so that files which are replaced (for example by an editor saving to a temporary file and renaming
+it) or created later are still seen.
+*/
+func watchFiles(ctx context.Context, fileNames []string, events chan<- struct{}) error {
+	inotifyFd, initErr := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+
+	if initErr != nil {
+		return initErr
+	}
+
+	watchedNames := make(map[int32]map[string]bool)
+
+	for _, fileName := range fileNames {
+		watchDescriptor, watchErr := syscall.InotifyAddWatch(inotifyFd, filepath.Dir(fileName), inotifyWatchMask)
+
+		if watchErr != nil {
+			syscall.Close(inotifyFd)
+			return watchErr
+		}
+
+		if _, exists := watchedNames[int32(watchDescriptor)]; !exists {
+			watchedNames[int32(watchDescriptor)] = make(map[string]bool)
+		}
+
+		watchedNames[int32(watchDescriptor)][filepath.Base(fileName)] = true
+	}
+
+	// As the descriptor is non-blocking, os.File reads through the runtime poller and Close interrupts a pending Read.
+	inotifyFile := os.NewFile(uintptr(inotifyFd), "inotify")
+
+	go func() {
+		<-ctx.Done()
+		inotifyFile.Close()
+	}()
+
+	go func() {
+		buffer := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+		for {
+			readLength, readErr := inotifyFile.Read(buffer)
+
+			if readErr != nil {
+				return
+			}
+
+			if hasWatchedEvent(buffer[:readLength], watchedNames) {
+				notifyChange(events)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func hasWatchedEvent(buffer []byte, watchedNames map[int32]map[string]bool) bool {
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buffer); {
+		// #nosec
+		event := (*syscall.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+		nameStart := offset + syscall.SizeofInotifyEvent
+		nameEnd := nameStart + int(event.Len)
+
+		if nameEnd > len(buffer) {
+			return false
+		}
+
+		name := string(buffer[nameStart:nameEnd])
+
+		for len(name) > 0 && name[len(name)-1] == 0 {
+			name = name[:len(name)-1]
+		}
+
+		if watchedNames[event.Wd][name] {
+			return true
+		}
+
+		offset = nameEnd
+	}
+
+	return false
+}
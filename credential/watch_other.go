@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package credential
+
+import (
+	"context"
+	"errors"
+)
+
+// watchFiles is only implemented with inotify on Linux, so every other platform falls back to polling.
+func watchFiles(ctx context.Context, fileNames []string, events chan<- struct{}) error {
+	return errors.New("watching files is not supported on this platform")
+}
@@ -0,0 +1,26 @@
+/*
+Copyright (c) 2020 engi.fyi Contributors, All Rights Reserved.
+
+Licensed under the MIT License (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	https://engi.fyi/mit-license/
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package dockercredential lets go-credentials be used as a docker credential helper, storing registry usernames and
+passwords in the same files as any other profile.
+
+docker talks to a helper by running it with one of the operations get, store, erase or list (see
+https://github.com/docker/docker-credential-helpers). Each server URL is stored as its own profile, named after the URL,
+such as https_index_docker_io_v1 for https://index.docker.io/v1/. The profile's Username and Password are the helper's
+Username and Secret, and the server URL itself is kept in the server_url attribute.
+*/
+package dockercredential
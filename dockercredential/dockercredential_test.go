@@ -0,0 +1,82 @@
+package dockercredential
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/credential"
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestRun(t *testing.T) {
+	assert, log, testFactory, _, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing the get, store, erase and list operations.")
+	serverUrl := "https://index.docker.io/v1/"
+
+	var response bytes.Buffer
+	assert.EqualError(Run(testFactory, OPERATION_GET, strings.NewReader(serverUrl+"\n"), &response), ERR_CREDENTIALS_NOT_FOUND)
+
+	storeRequest := `{"ServerURL": "` + serverUrl + `", "Username": "docker_user", "Secret": "docker_secret"}`
+	assert.NoError(Run(testFactory, OPERATION_STORE, strings.NewReader(storeRequest), &response))
+	assert.Equal("", response.String())
+	assert.NoError(Run(testFactory, OPERATION_GET, strings.NewReader(serverUrl+"\n"), &response))
+	assert.JSONEq(`{"ServerURL": "`+serverUrl+`", "Username": "docker_user", "Secret": "docker_secret"}`, response.String())
+
+	storedCredential, loadErr := credential.LoadFromProfile(ProfileName(serverUrl), testFactory)
+	assert.NoError(loadErr)
+	assert.Equal("https_index_docker_io_v1", storedCredential.Profile.Name)
+	assert.Equal("docker_secret", storedCredential.Password)
+
+	// Profiles that were not stored by the helper, or were stored for another URL with the same name, are not used.
+	otherCredential, newErr := credential.New(testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.NoError(otherCredential.Save())
+	assert.EqualError(Run(testFactory, OPERATION_GET, strings.NewReader("https://index.docker.io/v1"), &response), ERR_CREDENTIALS_NOT_FOUND)
+
+	response.Reset()
+	assert.NoError(Run(testFactory, OPERATION_LIST, strings.NewReader(""), &response))
+	assert.JSONEq(`{"`+serverUrl+`": "docker_user"}`, response.String())
+
+	assert.NoError(Run(testFactory, OPERATION_ERASE, strings.NewReader(serverUrl), &response))
+	assert.EqualError(Run(testFactory, OPERATION_ERASE, strings.NewReader(serverUrl), &response), ERR_CREDENTIALS_NOT_FOUND)
+	response.Reset()
+	assert.NoError(Run(testFactory, OPERATION_LIST, strings.NewReader(""), &response))
+	assert.JSONEq(`{}`, response.String())
+
+	assert.EqualError(Run(testFactory, OPERATION_STORE, strings.NewReader(`{"ServerURL": "`+serverUrl+`", "Username": "docker_user"}`), &response), ERR_USERNAME_AND_SECRET_REQUIRED)
+	assert.EqualError(Run(testFactory, OPERATION_GET, strings.NewReader(""), &response), ERR_SERVER_URL_REQUIRED)
+	assert.EqualError(Run(testFactory, "unknown", strings.NewReader(""), &response), ERR_UNKNOWN_OPERATION)
+}
+
+func TestStoreCollidingServerUrls(t *testing.T) {
+	assert, log, testFactory, _, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing that server URLs with the same profile name do not overwrite each other.")
+	firstUrl, secondUrl := "https://index.docker.io/v1/", "https://index.docker.io/v1"
+	assert.Equal(ProfileName(firstUrl), ProfileName(secondUrl))
+
+	assert.NoError(Store(testFactory, Credentials{ServerURL: firstUrl, Username: "first_user", Secret: "first_secret"}))
+	assert.NoError(Store(testFactory, Credentials{ServerURL: secondUrl, Username: "second_user", Secret: "second_secret"}))
+	assert.NoError(Store(testFactory, Credentials{ServerURL: secondUrl, Username: "second_user", Secret: "changed_secret"}))
+
+	firstCredentials, firstErr := Get(testFactory, firstUrl)
+	assert.NoError(firstErr)
+	assert.Equal(Credentials{ServerURL: firstUrl, Username: "first_user", Secret: "first_secret"}, *firstCredentials)
+	secondCredentials, secondErr := Get(testFactory, secondUrl)
+	assert.NoError(secondErr)
+	assert.Equal(Credentials{ServerURL: secondUrl, Username: "second_user", Secret: "changed_secret"}, *secondCredentials)
+
+	servers, listErr := List(testFactory)
+	assert.NoError(listErr)
+	assert.Equal(map[string]string{firstUrl: "first_user", secondUrl: "second_user"}, servers)
+
+	assert.NoError(Erase(testFactory, firstUrl))
+	_, erasedErr := Get(testFactory, firstUrl)
+	assert.EqualError(erasedErr, ERR_CREDENTIALS_NOT_FOUND)
+	secondCredentials, secondErr = Get(testFactory, secondUrl)
+	assert.NoError(secondErr)
+	assert.Equal("changed_secret", secondCredentials.Secret)
+}
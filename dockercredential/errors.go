@@ -0,0 +1,7 @@
+package dockercredential
+
+// ERR_CREDENTIALS_NOT_FOUND is the message docker looks for to tell that a helper has no credentials for a server.
+const ERR_CREDENTIALS_NOT_FOUND = "credentials not found in native keychain"
+const ERR_SERVER_URL_REQUIRED = "sorry docker must send a server URL"
+const ERR_USERNAME_AND_SECRET_REQUIRED = "sorry docker must send both a username and secret to store"
+const ERR_UNKNOWN_OPERATION = "sorry that is not a docker credential helper operation"
@@ -0,0 +1,210 @@
+package dockercredential
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/credential"
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+const OPERATION_GET = "get"
+const OPERATION_STORE = "store"
+const OPERATION_ERASE = "erase"
+const OPERATION_LIST = "list"
+
+const serverUrlKey = "server_url"
+
+/*
+Run carries out a docker credential helper operation, reading the request from reader and writing the reply to writer.
+store reads a Credentials object, get and erase read a server URL as plain text, and list reads nothing. get replies
+with a Credentials object, list with an object of server URLs to usernames, and store and erase reply with nothing. If
+there is nothing stored for the server URL given to get or erase, ERR_CREDENTIALS_NOT_FOUND is returned.
+*/
+func Run(sourceFactory *factory.Factory, operation string, reader io.Reader, writer io.Writer) error {
+	switch operation {
+	case OPERATION_STORE:
+		var myCredentials Credentials
+
+		if decodeErr := json.NewDecoder(reader).Decode(&myCredentials); decodeErr != nil {
+			return decodeErr
+		}
+
+		return Store(sourceFactory, myCredentials)
+	case OPERATION_GET:
+		serverUrl, readErr := readServerUrl(reader)
+
+		if readErr != nil {
+			return readErr
+		}
+
+		myCredentials, getErr := Get(sourceFactory, serverUrl)
+
+		if getErr != nil {
+			return getErr
+		}
+
+		return json.NewEncoder(writer).Encode(myCredentials)
+	case OPERATION_ERASE:
+		serverUrl, readErr := readServerUrl(reader)
+
+		if readErr != nil {
+			return readErr
+		}
+
+		return Erase(sourceFactory, serverUrl)
+	case OPERATION_LIST:
+		servers, listErr := List(sourceFactory)
+
+		if listErr != nil {
+			return listErr
+		}
+
+		return json.NewEncoder(writer).Encode(servers)
+	}
+
+	sourceFactory.Log.Error().Str("operation", operation).Msg(ERR_UNKNOWN_OPERATION)
+	return errors.New(ERR_UNKNOWN_OPERATION)
+}
+
+/*
+ProfileName returns the name of the profile that the credentials for serverUrl are stored in when it is free, which is
+the URL with any characters that cannot be used in a profile name replaced by underscores.
+*/
+func ProfileName(serverUrl string) string {
+	return global.SanitizeName(serverUrl)
+}
+
+// Get returns the stored credentials for serverUrl, or ERR_CREDENTIALS_NOT_FOUND if there are none.
+func Get(sourceFactory *factory.Factory, serverUrl string) (*Credentials, error) {
+	myCredential, _, loadErr := load(sourceFactory, serverUrl)
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	return &Credentials{
+		ServerURL: serverUrl,
+		Username:  myCredential.Username,
+		Secret:    myCredential.Password,
+	}, nil
+}
+
+/*
+Store saves the username and secret of myCredentials as the profile for its server URL, replacing any already stored. A
+new server URL is stored under ProfileName, or if another server URL already uses that name, under the name given by
+global.HashName for the URL.
+*/
+func Store(sourceFactory *factory.Factory, myCredentials Credentials) error {
+	if myCredentials.ServerURL == "" {
+		return errors.New(ERR_SERVER_URL_REQUIRED)
+	}
+
+	if myCredentials.Username == "" || myCredentials.Secret == "" {
+		return errors.New(ERR_USERNAME_AND_SECRET_REQUIRED)
+	}
+
+	myCredential, profileName, loadErr := load(sourceFactory, myCredentials.ServerURL)
+
+	if loadErr != nil && loadErr.Error() != ERR_CREDENTIALS_NOT_FOUND {
+		return loadErr
+	}
+
+	if myCredential == nil {
+		var newErr error
+		myCredential, newErr = credential.NewProfile(profileName, sourceFactory, myCredentials.Username, myCredentials.Secret)
+
+		if newErr != nil {
+			return newErr
+		}
+	}
+
+	myCredential.Username, myCredential.Password = myCredentials.Username, myCredentials.Secret
+
+	if setErr := myCredential.SetAttribute(serverUrlKey, myCredentials.ServerURL); setErr != nil {
+		return setErr
+	}
+
+	return myCredential.Save()
+}
+
+// Erase removes the profile for serverUrl, or returns ERR_CREDENTIALS_NOT_FOUND if there is none.
+func Erase(sourceFactory *factory.Factory, serverUrl string) error {
+	myCredential, _, loadErr := load(sourceFactory, serverUrl)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	return credential.DeleteProfile(myCredential.Profile.Name, sourceFactory)
+}
+
+/*
+List returns the username stored for every server URL. Profiles without a server_url attribute, which were not stored
+by the helper, are left out.
+*/
+func List(sourceFactory *factory.Factory) (map[string]string, error) {
+	servers := make(map[string]string)
+	profiles, listErr := serializer.ListProfiles(sourceFactory)
+
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	for _, profileName := range profiles {
+		myCredential, loadErr := credential.LoadFromProfile(profileName, sourceFactory)
+
+		if loadErr != nil {
+			return nil, loadErr
+		}
+
+		if serverUrl := myCredential.GetAttribute(serverUrlKey); serverUrl != "" {
+			servers[serverUrl] = myCredential.Username
+		}
+	}
+
+	return servers, nil
+}
+
+/*
+load returns the stored profile for serverUrl, or ERR_CREDENTIALS_NOT_FOUND if there is none, along with the name to
+store it under. As different server URLs can give the same profile name, the server URL stored with the profile must
+also match, and if the name is taken by another server URL, the name with a hash of the URL added is used instead.
+*/
+func load(sourceFactory *factory.Factory, serverUrl string) (*credential.Credential, string, error) {
+	if serverUrl == "" {
+		return nil, "", errors.New(ERR_SERVER_URL_REQUIRED)
+	}
+
+	profileName := ProfileName(serverUrl)
+	myCredential, freeName, loadErr := credential.LoadMatchingProfile(sourceFactory,
+		[]string{profileName, global.HashName(profileName, serverUrl)},
+		func(storedCredential *credential.Credential) bool {
+			return storedCredential.GetAttribute(serverUrlKey) == serverUrl
+		})
+
+	if loadErr != nil {
+		return nil, "", loadErr
+	}
+
+	if myCredential == nil {
+		return nil, freeName, errors.New(ERR_CREDENTIALS_NOT_FOUND)
+	}
+
+	return myCredential, myCredential.Profile.Name, nil
+}
+
+func readServerUrl(reader io.Reader) (string, error) {
+	contents, readErr := ioutil.ReadAll(reader)
+
+	if readErr != nil {
+		return "", readErr
+	}
+
+	return strings.TrimSpace(string(contents)), nil
+}
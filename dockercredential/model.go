@@ -0,0 +1,10 @@
+package dockercredential
+
+/*
+Credentials is the JSON object docker sends to store and receives from get.
+*/
+type Credentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
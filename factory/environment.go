@@ -0,0 +1,125 @@
+package factory
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+SetEnvironment replaces the environment used by the env output type. Serializing to env writes into this map, and
+deserializing from env reads from it, so an application (or a test) can supply its own variables without touching the
+environment of the current process. Passing nil resets the Factory to an empty environment.
+*/
+func (thisFactory *Factory) SetEnvironment(environment map[string]string) {
+	if environment == nil {
+		environment = make(map[string]string)
+	}
+
+	thisFactory.Log.Trace().Int("variables", len(environment)).Msg("Environment set.")
+	thisFactory.environment = environment
+}
+
+/*
+GetEnvironment returns the environment used by the env output type. Unless SetEnvironment has been called, this is a
+copy of the environment of the current process taken when the Factory was initialized. The returned map is the one used
+by the Factory, so changes to it are seen by subsequent loads.
+*/
+func (thisFactory *Factory) GetEnvironment() map[string]string {
+	if thisFactory.environment == nil {
+		thisFactory.environment = make(map[string]string)
+	}
+
+	return thisFactory.environment
+}
+
+/*
+ApplyEnvironment copies every variable in the Factory's environment that belongs to this application (i.e. that starts
+with APPLICATION_NAME::) into the environment of the current process. This is the only time the env output type
+modifies the process environment, unless UseEnvironment is set on the Factory, in which case it is applied after every
+save.
+*/
+func (thisFactory *Factory) ApplyEnvironment() error {
+	prefix := thisFactory.GetEnvironmentPrefix()
+	environment := thisFactory.GetEnvironment()
+	keys := make([]string, 0, len(environment))
+
+	for key := range environment {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.HasPrefix(strings.ToUpper(key), prefix) {
+			thisFactory.Log.Trace().Str("key", key).Msg("Applying variable to process environment.")
+			setErr := os.Setenv(key, environment[key])
+
+			if setErr != nil {
+				thisFactory.Log.Error().Err(setErr).Str("key", key).Msg("Error applying variable to process environment.")
+				return setErr
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+ClearEnvironment removes every variable belonging to this application, for all profiles, from both the Factory's
+environment and the environment of the current process. Long-running applications can use this to drop credentials
+once they are no longer needed.
+*/
+func (thisFactory *Factory) ClearEnvironment() error {
+	return thisFactory.UnsetEnvironment(thisFactory.GetEnvironmentPrefix())
+}
+
+/*
+UnsetEnvironment removes every variable whose name starts with prefix (ignoring case) from both the Factory's
+environment and the environment of the current process.
+*/
+func (thisFactory *Factory) UnsetEnvironment(prefix string) error {
+	prefix = strings.ToUpper(prefix)
+
+	for key := range thisFactory.GetEnvironment() {
+		if strings.HasPrefix(strings.ToUpper(key), prefix) {
+			thisFactory.Log.Trace().Str("key", key).Msg("Removing variable from environment.")
+			delete(thisFactory.environment, key)
+		}
+	}
+
+	for key := range loadProcessEnvironment() {
+		if strings.HasPrefix(strings.ToUpper(key), prefix) {
+			thisFactory.Log.Trace().Str("key", key).Msg("Removing variable from process environment.")
+			unsetErr := os.Unsetenv(key)
+
+			if unsetErr != nil {
+				thisFactory.Log.Error().Err(unsetErr).Str("key", key).Msg("Error removing variable from process environment.")
+				return unsetErr
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+GetEnvironmentPrefix returns the prefix that every environment variable belonging to this application starts with.
+*/
+func (thisFactory *Factory) GetEnvironmentPrefix() string {
+	return strings.ToUpper(thisFactory.ApplicationName) + "::"
+}
+
+func loadProcessEnvironment() map[string]string {
+	environment := make(map[string]string)
+
+	for _, entry := range os.Environ() {
+		splitIndex := strings.Index(entry, "=")
+
+		if splitIndex > 0 {
+			environment[entry[:splitIndex]] = entry[splitIndex+1:]
+		}
+	}
+
+	return environment
+}
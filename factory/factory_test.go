@@ -129,6 +129,25 @@ func TestAlternates(t *testing.T) {
 	os.RemoveAll(testFactory.ParentDirectory)
 }
 
+func TestAlternatesAws(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing the alternates of the aws output type.")
+	testFactory, factoryErr := New(global.TEST_VAR_APPLICATION_NAME)
+	assert.NoError(factoryErr)
+	assert.NoError(testFactory.SetAlternates(global.TEST_VAR_USERNAME_ALTERNATE_LABEL, global.TEST_VAR_PASSWORD_ALTERNATE_LABEL))
+
+	assert.NoError(testFactory.SetOutputType(global.OUTPUT_TYPE_AWS))
+	alternateUsername, alternatePassword := testFactory.GetAlternates()
+	assert.Equal(global.AWS_ACCESS_KEY_ID_LABEL, alternateUsername)
+	assert.Equal(global.AWS_SECRET_ACCESS_KEY_LABEL, alternatePassword)
+
+	assert.NoError(testFactory.SetOutputType(global.OUTPUT_TYPE_INI))
+	alternateUsername, alternatePassword = testFactory.GetAlternates()
+	assert.Equal(global.TEST_VAR_USERNAME_ALTERNATE_LABEL, alternateUsername)
+	assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE_LABEL, alternatePassword)
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
 func TestFactoryLogging(t *testing.T) {
 	assert, log := global.InitTest(t)
 	log.Info().Msg("Testing factory logging methods.")
@@ -163,3 +182,61 @@ func testGetParentDirectory(applicationName string) string {
 	homeDirectory, _ := os.UserHomeDir()
 	return homeDirectory + "/." + applicationName + "/"
 }
+
+func TestEnvironment(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing the environment used by the env output type.")
+	os.Setenv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL, global.TEST_VAR_USERNAME)
+	testFactory, factoryErr := New(global.TEST_VAR_APPLICATION_NAME)
+	assert.NoError(factoryErr)
+	os.Unsetenv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
+	assert.Equal(global.TEST_VAR_USERNAME, testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL])
+
+	testFactory.SetEnvironment(map[string]string{
+		global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL: global.TEST_VAR_PASSWORD,
+		"UNRELATED_VARIABLE":                       global.TEST_VAR_ATTRIBUTE_VALUE,
+	})
+	_, exists := testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL]
+	assert.False(exists)
+	assert.Equal(global.TEST_VAR_ENVIRONMENT_APPLICATION_NAME+"::", testFactory.GetEnvironmentPrefix())
+
+	applyErr := testFactory.ApplyEnvironment()
+	assert.NoError(applyErr)
+	value, exists := os.LookupEnv(global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL)
+	assert.True(exists)
+	assert.Equal(global.TEST_VAR_PASSWORD, value)
+	_, exists = os.LookupEnv("UNRELATED_VARIABLE")
+	assert.False(exists)
+	os.Unsetenv(global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL)
+
+	testFactory.SetEnvironment(nil)
+	assert.Empty(testFactory.GetEnvironment())
+	assert.Empty((&Factory{}).GetEnvironment())
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
+func TestClearEnvironment(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing clearing the application from the environment.")
+	os.Setenv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL, global.TEST_VAR_USERNAME)
+	os.Setenv("UNRELATED_VARIABLE", global.TEST_VAR_ATTRIBUTE_VALUE)
+	testFactory, factoryErr := New(global.TEST_VAR_APPLICATION_NAME)
+	assert.NoError(factoryErr)
+	testFactory.GetEnvironment()["mtca::second_profile::username"] = global.TEST_VAR_USERNAME
+
+	clearErr := testFactory.ClearEnvironment()
+	assert.NoError(clearErr)
+	_, exists := os.LookupEnv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
+	assert.False(exists)
+	_, exists = testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL]
+	assert.False(exists)
+	_, exists = testFactory.GetEnvironment()["mtca::second_profile::username"]
+	assert.False(exists)
+	_, exists = os.LookupEnv("UNRELATED_VARIABLE")
+	assert.True(exists)
+	_, exists = testFactory.GetEnvironment()["UNRELATED_VARIABLE"]
+	assert.True(exists)
+
+	os.Unsetenv("UNRELATED_VARIABLE")
+	os.RemoveAll(testFactory.ParentDirectory)
+}
@@ -92,9 +92,10 @@ func getLogLevel(logLevel string) zerolog.Level {
 }
 
 /*
-Initialize sets computed properties a Factory object. Specifically, it sets the value of ParentDirectory, ConfigDirectory and
-CredentialFile. If ParentDirectory does not exist, it will also create it. Alternates is also initialized as
-an empty map and the Initialized flag is set to true. The logger for the Factory is also initialized here.
+Initialize sets computed properties a Factory object. Specifically, it sets the value of ParentDirectory,
+ConfigDirectory, HistoryDirectory and CredentialFile. If ParentDirectory does not exist, it will also create it.
+Alternates is also initialized as an empty map, the environment used by the env output type is copied from the current
+process and the Initialized flag is set to true. The logger for the Factory is also initialized here.
 */
 func (thisFactory *Factory) Initialize() error {
 	thisFactory.initLogger()
@@ -110,6 +111,7 @@ func (thisFactory *Factory) Initialize() error {
 	thisFactory.ParentDirectory = homeDirectory + "/." + strings.ToLower(thisFactory.ApplicationName) + "/"
 	thisFactory.ConfigDirectory = thisFactory.ParentDirectory + "config/"
 	thisFactory.CredentialFile = thisFactory.ParentDirectory + "credentials"
+	thisFactory.HistoryDirectory = thisFactory.ParentDirectory + "history/"
 
 	if _, pdsErr := os.Stat(thisFactory.ParentDirectory); os.IsNotExist(pdsErr) {
 		thisFactory.Log.Trace().Str("parent", thisFactory.ParentDirectory).Msg("Creating parent directory.")
@@ -148,6 +150,7 @@ func (thisFactory *Factory) Initialize() error {
 	}
 
 	thisFactory.alternates = make(map[string]string)
+	thisFactory.environment = loadProcessEnvironment()
 	thisFactory.Initialized = true
 	thisFactory.Log.Trace().Msg("Credential initialization complete.")
 	return nil
@@ -168,9 +171,14 @@ func (thisFactory *Factory) SetAlternateUsername(alternateUsername string) error
 }
 
 /*
-GetAlternateUsername gets a label to be used in lieu of username in environment variables.
+GetAlternateUsername gets a label to be used in lieu of username in environment variables. With the aws output type
+this is always aws_access_key_id, the key the username is stored under in the AWS shared credentials file.
 */
 func (thisFactory *Factory) GetAlternateUsername() string {
+	if thisFactory.OutputType == global.OUTPUT_TYPE_AWS {
+		return global.AWS_ACCESS_KEY_ID_LABEL
+	}
+
 	if val, exists := thisFactory.alternates["username"]; exists {
 		thisFactory.Log.Trace().Str("username", thisFactory.alternates["username"]).Msg("Found alternate.")
 		return val
@@ -195,16 +203,21 @@ func (thisFactory *Factory) SetAlternatePassword(alternatePassword string) error
 }
 
 /*
-GetAlternates returns the username and password.
+GetAlternates returns the username and password labels (see GetAlternateUsername and GetAlternatePassword).
 */
 func (thisFactory *Factory) GetAlternates() (string, string) {
 	return thisFactory.GetAlternateUsername(), thisFactory.GetAlternatePassword()
 }
 
 /*
-GetAlternatePassword sets a label to be used in lieu of password in environment variables.
+GetAlternatePassword sets a label to be used in lieu of password in environment variables. With the aws output type
+this is always aws_secret_access_key, the key the password is stored under in the AWS shared credentials file.
 */
 func (thisFactory *Factory) GetAlternatePassword() string {
+	if thisFactory.OutputType == global.OUTPUT_TYPE_AWS {
+		return global.AWS_SECRET_ACCESS_KEY_LABEL
+	}
+
 	if val, exists := thisFactory.alternates["password"]; exists {
 		thisFactory.Log.Trace().Str("password", thisFactory.alternates["password"]).Msg("Found alternate.")
 		return val
@@ -216,12 +229,13 @@ func (thisFactory *Factory) GetAlternatePassword() string {
 
 /*
 SetOutputType determines which of the supported file types Credentials should be serialized to file as. The currently
-supported file types are ini with plans to implement json.
+supported output types are ini, json, env and aws (the AWS shared credentials and config files, see serializer.ToAws).
 */
 func (thisFactory *Factory) SetOutputType(outputType string) error {
 	if outputType == global.OUTPUT_TYPE_INI ||
 		outputType == global.OUTPUT_TYPE_JSON ||
-		outputType == global.OUTPUT_TYPE_ENV {
+		outputType == global.OUTPUT_TYPE_ENV ||
+		outputType == global.OUTPUT_TYPE_AWS {
 		thisFactory.Log.Trace().Str("output_type", outputType).Msg("Output type set.")
 		thisFactory.OutputType = outputType
 		return nil
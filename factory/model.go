@@ -8,18 +8,24 @@ import "github.com/rs/zerolog"
 // Application Name: this is the name of the application.
 // ParentDirectory: automatically set to ~/.application_name
 // CredentialFile: automatically set to configurationDirectory + "/credentials".
-// UseEnvironment: can I load variables into the environment. Only set this if you intend on use LoadEnv.
+// UseEnvironment: should the env output type also apply saved variables to the process environment (see ApplyEnvironment).
 // Initialized: has all of my configuration been initialized correctly?
 // Output Type: the file type that the CredentialFile contents should be.
 // Alternates: if username or password are set, those names are set
+// Environment: the variables read and written by the env output type (see SetEnvironment).
+// HistoryDirectory: automatically set to ParentDirectory + "history/".
+// HistorySize: how many versions of each profile Credential.Save keeps in HistoryDirectory, 0 (the default) keeps none.
 type Factory struct {
-	ApplicationName string
-	ParentDirectory string
-	CredentialFile  string
-	ConfigDirectory string
-	UseEnvironment  bool
-	Initialized     bool
-	OutputType      string
-	Log             *zerolog.Logger
-	alternates      map[string]string
+	ApplicationName  string
+	ParentDirectory  string
+	CredentialFile   string
+	ConfigDirectory  string
+	HistoryDirectory string
+	HistorySize      int
+	UseEnvironment   bool
+	Initialized      bool
+	OutputType       string
+	Log              *zerolog.Logger
+	alternates       map[string]string
+	environment      map[string]string
 }
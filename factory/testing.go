@@ -0,0 +1,62 @@
+package factory
+
+import (
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+/*
+InitTestHome is used by test functions that need a home directory of their own. Along with what global.InitTest does,
+it points HOME, and USERPROFILE which is used on Windows, at a new temporary directory, and returns that directory and a
+Factory for global.TEST_VAR_APPLICATION_NAME rooted in it. The returned function removes the directory and restores
+the environment, and should be deferred.
+*/
+func InitTestHome(t *testing.T) (*assert.Assertions, zerolog.Logger, *Factory, string, func()) {
+	assert, logger := global.InitTest(t)
+	homeDirectory, tempErr := ioutil.TempDir("", global.TEST_VAR_APPLICATION_NAME)
+
+	if tempErr != nil {
+		t.Fatal(tempErr)
+	}
+
+	var restores []func()
+
+	for _, environmentKey := range []string{"HOME", "USERPROFILE"} {
+		restores = append(restores, setTestEnvironment(environmentKey, homeDirectory))
+	}
+
+	cleanup := func() {
+		for _, restore := range restores {
+			restore()
+		}
+
+		os.RemoveAll(homeDirectory)
+	}
+
+	testFactory, factoryErr := New(global.TEST_VAR_APPLICATION_NAME)
+
+	if factoryErr != nil {
+		cleanup()
+		t.Fatal(factoryErr)
+	}
+
+	return assert, logger, testFactory, homeDirectory, cleanup
+}
+
+// setTestEnvironment sets environmentKey to value, and returns a function that restores its original value.
+func setTestEnvironment(environmentKey string, value string) func() {
+	original, wasSet := os.LookupEnv(environmentKey)
+	os.Setenv(environmentKey, value)
+
+	return func() {
+		if wasSet {
+			os.Setenv(environmentKey, original)
+		} else {
+			os.Unsetenv(environmentKey)
+		}
+	}
+}
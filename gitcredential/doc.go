@@ -0,0 +1,26 @@
+/*
+Copyright (c) 2020 engi.fyi Contributors, All Rights Reserved.
+
+Licensed under the MIT License (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	https://engi.fyi/mit-license/
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package gitcredential lets go-credentials be used as a git credential helper, storing git HTTPS usernames and tokens in
+the same files as any other profile.
+
+git talks to a helper by running it with one of the operations get, store or erase, and writing a request of key=value
+lines to its standard input (see https://git-scm.com/docs/git-credential). Each protocol and host is stored as its own
+profile, named after both, such as https_github_com for https://github.com. If git is configured to send paths (with
+credential.useHttpPath), the credential for each path is kept in a section of the host's profile instead.
+*/
+package gitcredential
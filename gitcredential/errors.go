@@ -0,0 +1,6 @@
+package gitcredential
+
+const ERR_INVALID_LINE = "sorry a line of the git credential request is not in the form key=value"
+const ERR_INVALID_URL = "sorry the url in the git credential request could not be parsed"
+const ERR_HOST_REQUIRED = "sorry the git credential request must include a host"
+const ERR_USERNAME_AND_PASSWORD_REQUIRED = "sorry git must send both a username and password to store"
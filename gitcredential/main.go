@@ -0,0 +1,214 @@
+package gitcredential
+
+import (
+	"errors"
+	"io"
+
+	"github.com/engi-fyi/go-credentials/credential"
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+const OPERATION_GET = "get"
+const OPERATION_STORE = "store"
+const OPERATION_ERASE = "erase"
+
+// The credential for a path is kept in the path's section, as username and password can only be set on the profile.
+const pathUsernameKey = "path_username"
+const pathPasswordKey = "path_password"
+
+/*
+Run carries out a git credential helper operation, reading the request from reader and writing the reply to writer. The
+operations are get, store and erase. As git expects of a helper, any other operation is ignored.
+*/
+func Run(sourceFactory *factory.Factory, operation string, reader io.Reader, writer io.Writer) error {
+	if operation != OPERATION_GET && operation != OPERATION_STORE && operation != OPERATION_ERASE {
+		sourceFactory.Log.Debug().Str("operation", operation).Msg("Ignoring unknown git credential operation.")
+		return nil
+	}
+
+	request, readErr := ReadRequest(reader)
+
+	if readErr != nil {
+		sourceFactory.Log.Error().Err(readErr).Msg("Unable to read git credential request.")
+		return readErr
+	}
+
+	switch operation {
+	case OPERATION_STORE:
+		return Store(sourceFactory, request)
+	case OPERATION_ERASE:
+		return Erase(sourceFactory, request)
+	}
+
+	response, getErr := Get(sourceFactory, request)
+
+	if getErr != nil || response == nil {
+		return getErr
+	}
+
+	return response.Write(writer)
+}
+
+/*
+ProfileName returns the name of the profile that the credentials for a protocol and host are stored in when it is free,
+which is both of them joined with any characters that cannot be used in a profile name replaced by underscores.
+*/
+func ProfileName(protocol string, host string) string {
+	return global.SanitizeName(protocol + "_" + host)
+}
+
+/*
+SectionName returns the name of the section of a host's profile that the credentials for path are stored in.
+*/
+func SectionName(path string) string {
+	return global.SanitizeName(path)
+}
+
+/*
+Get returns the stored credential that matches request, or nil if there is none. If request has a path and a credential
+has been stored for it, that credential is returned, otherwise the host's credential is used. If request has a username,
+only a credential for that username matches.
+*/
+func Get(sourceFactory *factory.Factory, request *Request) (*Request, error) {
+	myCredential, _, loadErr := load(sourceFactory, request)
+
+	if loadErr != nil || myCredential == nil {
+		return nil, loadErr
+	}
+
+	response := *request
+	response.Username, response.Password = myCredential.Username, myCredential.Password
+
+	if pathSection := findPath(myCredential, request); pathSection != nil {
+		response.Username = pathSection.GetAttribute(pathUsernameKey)
+		response.Password = pathSection.GetAttribute(pathPasswordKey)
+	}
+
+	if request.Username != "" && request.Username != response.Username {
+		return nil, nil
+	}
+
+	return &response, nil
+}
+
+/*
+Store saves the username and password of request. If request has a path, they are stored for the path only, unless the
+host has no credential yet, in which case they are used for the host as well. A new host is stored under ProfileName,
+or if another host already uses that name, under the name given by global.HashName for the protocol and host.
+*/
+func Store(sourceFactory *factory.Factory, request *Request) error {
+	if request.Username == "" || request.Password == "" {
+		return errors.New(ERR_USERNAME_AND_PASSWORD_REQUIRED)
+	}
+
+	myCredential, profileName, loadErr := load(sourceFactory, request)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if myCredential == nil {
+		var newErr error
+		myCredential, newErr = credential.NewProfile(profileName, sourceFactory, request.Username, request.Password)
+
+		if newErr != nil {
+			return newErr
+		}
+	} else if request.Path == "" {
+		myCredential.Username, myCredential.Password = request.Username, request.Password
+	}
+
+	attributes := [][2]string{{protocolKey, request.Protocol}, {hostKey, request.Host}}
+
+	for _, attribute := range attributes {
+		if setErr := myCredential.SetAttribute(attribute[0], attribute[1]); setErr != nil {
+			return setErr
+		}
+	}
+
+	if request.Path != "" {
+		pathSection := myCredential.Section(SectionName(request.Path))
+		attributes = [][2]string{{pathKey, request.Path}, {pathUsernameKey, request.Username}, {pathPasswordKey, request.Password}}
+
+		for _, attribute := range attributes {
+			if setErr := pathSection.SetAttribute(attribute[0], attribute[1]); setErr != nil {
+				return setErr
+			}
+		}
+	}
+
+	return myCredential.Save()
+}
+
+/*
+Erase removes the stored credential that matches request, which is the credential Get would return. If request has a
+username or password, the stored credential is only removed if they match, so that a credential which has since been
+replaced is not removed. Erasing the host's credential removes its profile, including the credentials of every path.
+*/
+func Erase(sourceFactory *factory.Factory, request *Request) error {
+	myCredential, _, loadErr := load(sourceFactory, request)
+
+	if loadErr != nil || myCredential == nil {
+		return loadErr
+	}
+
+	if pathSection := findPath(myCredential, request); pathSection != nil {
+		if !matches(request, pathSection.GetAttribute(pathUsernameKey), pathSection.GetAttribute(pathPasswordKey)) {
+			return nil
+		}
+
+		for _, key := range []string{pathKey, pathUsernameKey, pathPasswordKey} {
+			if deleteErr := pathSection.DeleteAttribute(key); deleteErr != nil {
+				return deleteErr
+			}
+		}
+
+		return myCredential.Save()
+	}
+
+	if !matches(request, myCredential.Username, myCredential.Password) {
+		return nil
+	}
+
+	return credential.DeleteProfile(myCredential.Profile.Name, sourceFactory)
+}
+
+/*
+load returns the stored profile for the protocol and host of request, or nil if there is none, along with the name to
+store it under. As different hosts can give the same profile name, the protocol and host stored with the profile must
+also match, and if the name is taken by another host, the name with a hash of the protocol and host added is used
+instead.
+*/
+func load(sourceFactory *factory.Factory, request *Request) (*credential.Credential, string, error) {
+	if request.Host == "" {
+		return nil, "", errors.New(ERR_HOST_REQUIRED)
+	}
+
+	profileName := ProfileName(request.Protocol, request.Host)
+	return credential.LoadMatchingProfile(sourceFactory,
+		[]string{profileName, global.HashName(profileName, request.Protocol+"://"+request.Host)},
+		func(storedCredential *credential.Credential) bool {
+			return storedCredential.GetAttribute(protocolKey) == request.Protocol && storedCredential.GetAttribute(hostKey) == request.Host
+		})
+}
+
+// findPath returns the section holding the credential for the path of request, or nil if there is none.
+func findPath(myCredential *credential.Credential, request *Request) *credential.Credential {
+	if request.Path == "" {
+		return nil
+	}
+
+	pathSection := myCredential.Section(SectionName(request.Path))
+
+	if pathSection.GetAttribute(pathKey) != request.Path {
+		return nil
+	}
+
+	return pathSection
+}
+
+func matches(request *Request, username string, password string) bool {
+	return (request.Username == "" || request.Username == username) &&
+		(request.Password == "" || request.Password == password)
+}
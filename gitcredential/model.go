@@ -0,0 +1,13 @@
+package gitcredential
+
+/*
+Request holds the fields of a git credential request that are used to find and store credentials. Fields that git
+sends but go-credentials does not store, such as capability[] or wwwauth[], are ignored.
+*/
+type Request struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
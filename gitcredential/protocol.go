@@ -0,0 +1,113 @@
+package gitcredential
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+const protocolKey = "protocol"
+const hostKey = "host"
+const pathKey = "path"
+const usernameKey = "username"
+const passwordKey = "password"
+const urlKey = "url"
+
+/*
+ReadRequest reads a git credential request from reader, which ends at a blank line or the end of the input. A url field
+is split into the fields it contains, and fields that come after it override them, in the same way as git itself.
+*/
+func ReadRequest(reader io.Reader) (*Request, error) {
+	request := &Request{}
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if line == "" {
+			break
+		}
+
+		separator := strings.Index(line, "=")
+
+		if separator < 1 {
+			return nil, errors.New(ERR_INVALID_LINE)
+		}
+
+		key, value := line[:separator], line[separator+1:]
+
+		switch key {
+		case protocolKey:
+			request.Protocol = value
+		case hostKey:
+			request.Host = value
+		case pathKey:
+			request.Path = value
+		case usernameKey:
+			request.Username = value
+		case passwordKey:
+			request.Password = value
+		case urlKey:
+			if urlErr := request.setUrl(value); urlErr != nil {
+				return nil, urlErr
+			}
+		}
+	}
+
+	if scanErr := scanner.Err(); scanErr != nil {
+		return nil, scanErr
+	}
+
+	return request, nil
+}
+
+func (thisRequest *Request) setUrl(rawUrl string) error {
+	parsedUrl, parseErr := url.Parse(rawUrl)
+
+	if parseErr != nil || parsedUrl.Scheme == "" {
+		return errors.New(ERR_INVALID_URL)
+	}
+
+	thisRequest.Protocol = parsedUrl.Scheme
+	thisRequest.Host = parsedUrl.Host
+	thisRequest.Path = strings.TrimPrefix(parsedUrl.Path, "/")
+
+	if parsedUrl.User != nil {
+		thisRequest.Username = parsedUrl.User.Username()
+
+		if password, hasPassword := parsedUrl.User.Password(); hasPassword {
+			thisRequest.Password = password
+		}
+	}
+
+	return nil
+}
+
+/*
+Write writes the request to writer as key=value lines, leaving out any fields that are blank. This is the reply to a
+get operation.
+*/
+func (thisRequest *Request) Write(writer io.Writer) error {
+	fields := [][2]string{
+		{protocolKey, thisRequest.Protocol},
+		{hostKey, thisRequest.Host},
+		{pathKey, thisRequest.Path},
+		{usernameKey, thisRequest.Username},
+		{passwordKey, thisRequest.Password},
+	}
+
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+
+		if _, writeErr := fmt.Fprintf(writer, "%s=%s\n", field[0], field[1]); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	return nil
+}
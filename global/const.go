@@ -7,9 +7,39 @@ const OUTPUT_TYPE_ENV = "env"
 const USERNAME_LABEL = "username"
 const OUTPUT_TYPE_INVALID = "nri"
 const REGEX_KEY_NAME = "(?m)^[0-9A-Za-z_]+$"
+const REGEX_AWS_PROFILE_NAME = "(?m)^[0-9A-Za-z_.-]+$"
 const NO_SECTION_KEY = "DEFAULT"
 const DEFAULT_PROFILE_NAME = "default"
 const INDENT_JSON = "    "
 const LOG_LEVEL_ENVIRONMENT_KEY = "GO_CREDS_LOG_LEVEL"
 const LOG_OUTPUT_TYPE_ENV_KEY = "GO_CREDS_LOG_OUTPUT_TYPE"
 const SECTION_NAME_BLANK = "__SECTION_NAME_BLANK__"
+const EXPORT_TYPE_DOTENV = "dotenv"
+const EXPORT_TYPE_POSIX = "posix"
+const EXPORT_TYPE_FISH = "fish"
+const FORMAT_VERSION = "1.0"
+const FORMAT_VERSION_LABEL = "format_version"
+const FORMAT_SECTION_NAME = "__GO_CREDENTIALS__"
+const CHANGE_TYPE_ADDED = "added"
+const CHANGE_TYPE_MODIFIED = "modified"
+const CHANGE_TYPE_DELETED = "deleted"
+const REDACTED_VALUE = "********"
+const SOURCE_EXPLICIT = "explicit"
+const SOURCE_ENVIRONMENT = "environment"
+const SOURCE_FILE = "file"
+const SOURCE_PROMPT = "prompt"
+const OUTPUT_TYPE_AWS = "aws"
+const AWS_ACCESS_KEY_ID_LABEL = "aws_access_key_id"
+const AWS_SECRET_ACCESS_KEY_LABEL = "aws_secret_access_key"
+const AWS_CREDENTIALS_FILE_ENVIRONMENT_KEY = "AWS_SHARED_CREDENTIALS_FILE"
+const AWS_CONFIG_FILE_ENVIRONMENT_KEY = "AWS_CONFIG_FILE"
+const AUTH_SCHEME_BASIC = "basic"
+const AUTH_SCHEME_BEARER = "bearer"
+const AUTH_SCHEME_API_KEY = "api_key"
+const DEFAULT_API_KEY_HEADER = "X-API-Key"
+const OAUTH2_SECTION_NAME = "oauth2"
+const OAUTH2_ACCESS_TOKEN_KEY = "access_token"
+const OAUTH2_REFRESH_TOKEN_KEY = "refresh_token"
+const OAUTH2_TOKEN_TYPE_KEY = "token_type"
+const OAUTH2_EXPIRY_KEY = "expiry"
+const OAUTH2_TOKEN_URL_KEY = "token_url"
@@ -28,6 +28,7 @@ const TEST_VAR_USERNAME = "a_test_username"
 const TEST_VAR_PASSWORD = "as=/sle\\sowkjg@!"
 const TEST_VAR_BAD_ATTRIBUTE_NAME = "a_ /test_attribute"
 const TEST_VAR_ATTRIBUTE_VALUE = "a global attribute value"
+const TEST_VAR_ATTRIBUTE_VALUE_CHANGED = "a changed attribute value"
 const TEST_VAR_USERNAME_ALTERNATE = "another_test_username"
 const TEST_VAR_PASSWORD_ALTERNATE = ".YaJ5XAA${hh8^C"
 
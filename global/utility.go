@@ -1,13 +1,19 @@
 package global
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 )
 
+var invalidNameCharacters = regexp.MustCompile("[^0-9A-Za-z]+")
+
 // InitTest is used by test functions to initialize logger settings and the assert pkg.
 func InitTest(t *testing.T) (*assert.Assertions, zerolog.Logger) {
 	os.Setenv(LOG_LEVEL_ENVIRONMENT_KEY, "trace")
@@ -15,3 +21,27 @@ func InitTest(t *testing.T) (*assert.Assertions, zerolog.Logger) {
 	logger := log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	return assert.New(t), logger
 }
+
+// SanitizeName lowercases value and replaces each run of characters that are not letters or digits with an
+// underscore, so that a host name, URL or similar can be used as a profile name.
+func SanitizeName(value string) string {
+	return strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(value), "_"), "_")
+}
+
+// HashName adds a short hash of value to name, so that values which SanitizeName turns into the same name can still be
+// given profile names of their own.
+func HashName(name string, value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return name + "_" + hex.EncodeToString(sum[:4])
+}
+
+// Contains returns true if value is one of values.
+func Contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,26 @@
+/*
+Copyright (c) 2020 engi.fyi Contributors, All Rights Reserved.
+
+Licensed under the MIT License (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	https://engi.fyi/mit-license/
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package netrc imports and exports the ~/.netrc files read by curl, ftp, git and the go command, so that the machines in
+them can be kept with go-credentials.
+
+Each machine entry is imported as a profile named after the machine, such as api_example_com for api.example.com, with
+the login and password as the profile's Username and Password and the machine itself in the host attribute. Exporting
+writes the profiles back as machine entries, updating the entries for their hosts and leaving everything else in the file
+as it was, including default and macdef entries, comments and machines that were not exported.
+*/
+package netrc
@@ -0,0 +1,4 @@
+package netrc
+
+const ERR_UNEXPECTED_END = "sorry the netrc file ended before a value was given for a keyword"
+const ERR_QUOTE_NOT_CLOSED = "sorry a quoted value in the netrc file was not closed"
@@ -0,0 +1,283 @@
+package netrc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/credential"
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+const netrcEnvironmentKey = "NETRC"
+const hostKey = "host"
+const accountKey = "account"
+
+/*
+DefaultFile returns the netrc file used by curl and the go command: the file named by the NETRC environment variable if
+it is set, otherwise ~/.netrc.
+*/
+func DefaultFile() (string, error) {
+	if fileName, ok := os.LookupEnv(netrcEnvironmentKey); ok && fileName != "" {
+		return fileName, nil
+	}
+
+	homeDirectory, homeErr := os.UserHomeDir()
+
+	if homeErr != nil {
+		return "", homeErr
+	}
+
+	return filepath.Join(homeDirectory, ".netrc"), nil
+}
+
+/*
+ProfileName returns the name of the profile that machine is imported as when it is free, which is the machine with any
+characters that cannot be used in a profile name replaced by underscores.
+*/
+func ProfileName(machine string) string {
+	return global.SanitizeName(machine)
+}
+
+/*
+Import saves every machine entry in fileName that has both a login and a password as a profile, and returns the saved
+Credentials. A profile that already exists is updated rather than replaced, so its other attributes are kept. An account
+given for a machine is saved in the account attribute. A new machine is saved under ProfileName, or if another machine
+already uses that name, under the name given by global.HashName for the machine. Machines without a login or password,
+and the default and macdef entries, are skipped.
+*/
+func Import(sourceFactory *factory.Factory, fileName string) ([]*credential.Credential, error) {
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	entries, parseErr := parse(string(contents))
+
+	if parseErr != nil {
+		sourceFactory.Log.Error().Err(parseErr).Str("file", fileName).Msg("Unable to parse netrc file.")
+		return nil, parseErr
+	}
+
+	var imported []*credential.Credential
+
+	for _, myEntry := range entries {
+		if myEntry.kind != kindMachine || myEntry.login == "" || myEntry.password == "" {
+			sourceFactory.Log.Debug().Str("kind", myEntry.kind).Str("machine", myEntry.machine).Msg("Skipping netrc entry.")
+			continue
+		}
+
+		myCredential, importErr := importEntry(sourceFactory, myEntry)
+
+		if importErr != nil {
+			return imported, importErr
+		}
+
+		imported = append(imported, myCredential)
+	}
+
+	return imported, nil
+}
+
+/*
+importEntry saves myEntry in the profile for its machine. As different machines can give the same profile name, the host
+stored with the profile must also match, unless it has none, and if the name is taken by another machine, the name with
+a hash of the machine added is used instead.
+*/
+func importEntry(sourceFactory *factory.Factory, myEntry *entry) (*credential.Credential, error) {
+	profileName := ProfileName(myEntry.machine)
+	myCredential, freeName, loadErr := credential.LoadMatchingProfile(sourceFactory,
+		[]string{profileName, global.HashName(profileName, myEntry.machine)},
+		func(storedCredential *credential.Credential) bool {
+			storedHost := storedCredential.GetAttribute(hostKey)
+			return storedHost == myEntry.machine || (storedHost == "" && storedCredential.Profile.Name == profileName)
+		})
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	if myCredential == nil {
+		myCredential, loadErr = credential.NewProfile(freeName, sourceFactory, myEntry.login, myEntry.password)
+
+		if loadErr != nil {
+			return nil, loadErr
+		}
+	}
+
+	myCredential.Username, myCredential.Password = myEntry.login, myEntry.password
+
+	if setErr := myCredential.SetAttribute(hostKey, myEntry.machine); setErr != nil {
+		return nil, setErr
+	}
+
+	if myEntry.account != "" {
+		if setErr := myCredential.SetAttribute(accountKey, myEntry.account); setErr != nil {
+			return nil, setErr
+		}
+	}
+
+	return myCredential, myCredential.Save()
+}
+
+/*
+Export writes each of profileNames to fileName as a machine entry, for the machine in the profile's host attribute, or
+the profile name if it has none. The login, password and account of the entry already in the file for that machine are
+updated in place, and machines that are not in the file are added before the default entry, which must be last.
+Everything else in the file, including comments and keywords this package does not use, is kept as it was. The
+file is created if it does not exist, and is always left with 0600 permissions, as it holds passwords. The new file is
+written alongside the old one and renamed over it (see serializer.WriteFileAtomic), so it is never left half written.
+*/
+func Export(sourceFactory *factory.Factory, fileName string, profileNames ...string) error {
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return readErr
+	}
+
+	entries, parseErr := parse(string(contents))
+
+	if parseErr != nil {
+		sourceFactory.Log.Error().Err(parseErr).Str("file", fileName).Msg("Unable to parse netrc file.")
+		return parseErr
+	}
+
+	for _, profileName := range profileNames {
+		myCredential, loadErr := credential.LoadFromProfile(profileName, sourceFactory)
+
+		if loadErr != nil {
+			return loadErr
+		}
+
+		entries = exportCredential(entries, myCredential)
+	}
+
+	var builder strings.Builder
+
+	for _, myEntry := range entries {
+		builder.WriteString(myEntry.raw)
+	}
+
+	return serializer.WriteFileAtomic(fileName, []byte(builder.String()), 0600)
+}
+
+// exportCredential replaces the machine entry for myCredential in entries, or adds it if there is none.
+func exportCredential(entries []*entry, myCredential *credential.Credential) []*entry {
+	machine := myCredential.GetAttribute(hostKey)
+
+	if machine == "" {
+		machine = myCredential.Profile.Name
+	}
+
+	exported := &entry{
+		kind:     kindMachine,
+		machine:  machine,
+		login:    myCredential.Username,
+		password: myCredential.Password,
+		account:  myCredential.GetAttribute(accountKey),
+	}
+	exported.raw = exported.render()
+	exported.spans = parseEntry(exported.raw).spans
+
+	for _, myEntry := range entries {
+		if myEntry.kind == kindMachine && myEntry.machine == machine {
+			myEntry.update(exported)
+			return entries
+		}
+	}
+
+	insertAt := len(entries)
+
+	for i, myEntry := range entries {
+		if myEntry.kind == kindDefault {
+			insertAt = i
+			break
+		}
+	}
+
+	// The entry before the new one must end with a newline, so that the new entry starts on a line of its own.
+	if previous := entries[insertAt-1]; previous.raw != "" && !strings.HasSuffix(previous.raw, "\n") {
+		previous.raw += "\n"
+	}
+
+	entries = append(entries, nil)
+	copy(entries[insertAt+1:], entries[insertAt:])
+	entries[insertAt] = exported
+	return entries
+}
+
+/*
+update sets the login, password and account of the entry to those of exported by editing raw in place. Values that have
+not changed are left as they were, missing keywords are added after the last value of the entry, and everything else,
+such as comments, formatting and keywords this package does not use, is kept. An account is only ever added or changed,
+never removed.
+*/
+func (thisEntry *entry) update(exported *entry) {
+	type edit struct {
+		start int
+		end   int
+		text  string
+	}
+
+	var edits []edit
+	var added string
+	insertAt := thisEntry.spans[kindMachine][1]
+	keywords := []string{keywordLogin, keywordPassword, keywordAccount}
+	current := []string{thisEntry.login, thisEntry.password, thisEntry.account}
+	values := []string{exported.login, exported.password, exported.account}
+	raw := thisEntry.raw
+
+	for i, keyword := range keywords {
+		span, exists := thisEntry.spans[keyword]
+
+		if exists && span[1] > insertAt {
+			insertAt = span[1]
+		}
+
+		if values[i] == "" || values[i] == current[i] {
+			continue
+		}
+
+		if exists {
+			edits = append(edits, edit{start: span[0], end: span[1], text: quote(values[i])})
+		} else {
+			added += " " + keyword + " " + quote(values[i])
+		}
+	}
+
+	if added != "" {
+		edits = append(edits, edit{start: insertAt, end: insertAt, text: added})
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+
+	for _, myEdit := range edits {
+		raw = raw[:myEdit.start] + myEdit.text + raw[myEdit.end:]
+	}
+
+	*thisEntry = *parseEntry(raw)
+}
+
+// parseEntry parses the raw text of a single machine entry, which has already been parsed or rendered without error.
+func parseEntry(raw string) *entry {
+	entries, _ := parse(raw)
+	return entries[len(entries)-1]
+}
+
+func (thisEntry *entry) render() string {
+	rendered := kindMachine + " " + quote(thisEntry.machine) + " " + keywordLogin + " " + quote(thisEntry.login) + " " +
+		keywordPassword + " " + quote(thisEntry.password)
+
+	if thisEntry.account != "" {
+		rendered += " " + keywordAccount + " " + quote(thisEntry.account)
+	}
+
+	return rendered + "\n"
+}
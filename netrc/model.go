@@ -0,0 +1,17 @@
+package netrc
+
+/*
+entry is one part of a netrc file: a machine or default entry with its login, password and account, a macdef entry, or
+the comments and whitespace before the first entry. raw is the text of the entry as it was read, including the
+whitespace and comments that follow it, so that entries which are not changed are written back exactly. spans holds
+where the value of each keyword starts and ends in raw, so that an entry can be updated without touching the rest of it.
+*/
+type entry struct {
+	kind     string
+	machine  string
+	login    string
+	password string
+	account  string
+	raw      string
+	spans    map[string][2]int
+}
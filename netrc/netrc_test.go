@@ -0,0 +1,164 @@
+package netrc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/credential"
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+const testNetrc = `# Written by hand.
+machine api.example.com
+	login api_user
+	password "api secret"
+	account billing
+
+machine ftp.example.com login anonymous
+
+macdef init
+cd /pub
+binary
+
+default login guest password guest
+`
+
+func TestParse(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing parsing netrc files.")
+	entries, parseErr := parse(testNetrc)
+	assert.NoError(parseErr)
+	assert.Len(entries, 5)
+
+	var joined strings.Builder
+	var kinds []string
+
+	for _, myEntry := range entries {
+		joined.WriteString(myEntry.raw)
+		kinds = append(kinds, myEntry.kind)
+	}
+
+	assert.Equal(testNetrc, joined.String())
+	assert.Equal([]string{kindPreamble, kindMachine, kindMachine, kindMacdef, kindDefault}, kinds)
+	assert.Equal(entry{kind: kindMachine, machine: "api.example.com", login: "api_user", password: "api secret", account: "billing", raw: entries[1].raw, spans: entries[1].spans}, *entries[1])
+	assert.Equal("\"api secret\"", entries[1].raw[entries[1].spans[keywordPassword][0]:entries[1].spans[keywordPassword][1]])
+	assert.Equal("init", entries[3].machine)
+	assert.Equal("guest", entries[4].password)
+
+	_, parseErr = parse("machine example.com login")
+	assert.EqualError(parseErr, ERR_UNEXPECTED_END)
+	_, parseErr = parse("machine example.com password \"secret")
+	assert.EqualError(parseErr, ERR_QUOTE_NOT_CLOSED)
+
+	entries, parseErr = parse("machine a password " + quote("a \"quoted\" \\ value"))
+	assert.NoError(parseErr)
+	assert.Equal("a \"quoted\" \\ value", entries[1].password)
+}
+
+func TestImportExport(t *testing.T) {
+	assert, log, testFactory, homeDirectory, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing importing and exporting netrc files.")
+
+	netrcFile, fileErr := DefaultFile()
+	assert.NoError(fileErr)
+	assert.Equal(filepath.Join(homeDirectory, ".netrc"), netrcFile)
+	assert.NoError(ioutil.WriteFile(netrcFile, []byte(testNetrc), 0644))
+
+	imported, importErr := Import(testFactory, netrcFile)
+	assert.NoError(importErr)
+	assert.Len(imported, 1)
+
+	apiCredential, loadErr := credential.LoadFromProfile("api_example_com", testFactory)
+	assert.NoError(loadErr)
+	assert.Equal("api_user", apiCredential.Username)
+	assert.Equal("api secret", apiCredential.Password)
+	assert.Equal("api.example.com", apiCredential.GetAttribute(hostKey))
+	assert.Equal("billing", apiCredential.GetAttribute(accountKey))
+
+	apiCredential.Password = "new_secret"
+	assert.NoError(apiCredential.Save())
+	newCredential, newErr := credential.NewProfile(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD)
+	assert.NoError(newErr)
+	assert.NoError(newCredential.Save())
+
+	assert.NoError(Export(testFactory, netrcFile, "api_example_com", global.TEST_VAR_FIRST_PROFILE_LABEL))
+	exported, readErr := ioutil.ReadFile(netrcFile)
+	assert.NoError(readErr)
+	expected := strings.Replace(testNetrc, "\tpassword \"api secret\"\n", "\tpassword new_secret\n", 1)
+	expected = strings.Replace(expected, "default", "machine "+global.TEST_VAR_FIRST_PROFILE_LABEL+" login "+global.TEST_VAR_USERNAME+
+		" password "+quote(global.TEST_VAR_PASSWORD)+"\ndefault", 1)
+	assert.Equal(expected, string(exported))
+
+	netrcInfo, statErr := os.Stat(netrcFile)
+	assert.NoError(statErr)
+	assert.Equal(os.FileMode(0600), netrcInfo.Mode().Perm())
+
+	// Exporting to a file that does not exist creates it.
+	newFile := filepath.Join(homeDirectory, "new.netrc")
+	assert.NoError(Export(testFactory, newFile, global.TEST_VAR_FIRST_PROFILE_LABEL))
+	exported, readErr = ioutil.ReadFile(newFile)
+	assert.NoError(readErr)
+	assert.Equal("machine "+global.TEST_VAR_FIRST_PROFILE_LABEL+" login "+global.TEST_VAR_USERNAME+" password "+quote(global.TEST_VAR_PASSWORD)+"\n", string(exported))
+}
+
+func TestExportKeepsComments(t *testing.T) {
+	assert, log, testFactory, homeDirectory, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing exporting keeps the comments and unknown keywords of the entries it updates.")
+
+	netrcFile := filepath.Join(homeDirectory, ".netrc")
+	original := "machine db.example.com login db_user password old_secret port 5432\n\n# The API key is rotated monthly.\nmachine api.example.com login api_user password api_secret\n"
+	assert.NoError(ioutil.WriteFile(netrcFile, []byte(original), 0600))
+	_, importErr := Import(testFactory, netrcFile)
+	assert.NoError(importErr)
+
+	dbCredential, loadErr := credential.LoadFromProfile("db_example_com", testFactory)
+	assert.NoError(loadErr)
+	dbCredential.Password = "new secret"
+	assert.NoError(dbCredential.SetAttribute(accountKey, "ops"))
+	assert.NoError(dbCredential.Save())
+
+	// Exporting twice gives the same file, as the updated entry is parsed again.
+	for i := 0; i < 2; i++ {
+		assert.NoError(Export(testFactory, netrcFile, "db_example_com", "api_example_com"))
+		exported, readErr := ioutil.ReadFile(netrcFile)
+		assert.NoError(readErr)
+		assert.Equal("machine db.example.com login db_user password \"new secret\" account ops port 5432\n\n# The API key is rotated monthly.\nmachine api.example.com login api_user password api_secret\n", string(exported))
+	}
+}
+
+func TestImportCollidingMachines(t *testing.T) {
+	assert, log, testFactory, homeDirectory, cleanup := factory.InitTestHome(t)
+	defer cleanup()
+	log.Info().Msg("Testing that machines with the same profile name do not overwrite each other.")
+	netrcFile := filepath.Join(homeDirectory, ".netrc")
+	contents := "machine api.example.com login first_user password first_secret\n" +
+		"machine api-example.com login second_user password second_secret\n"
+	assert.NoError(ioutil.WriteFile(netrcFile, []byte(contents), 0600))
+
+	imported, importErr := Import(testFactory, netrcFile)
+	assert.NoError(importErr)
+	assert.Len(imported, 2)
+	assert.Equal("api_example_com", imported[0].Profile.Name)
+	assert.Equal(global.HashName("api_example_com", "api-example.com"), imported[1].Profile.Name)
+
+	// Importing again updates the same profiles rather than adding more.
+	imported, importErr = Import(testFactory, netrcFile)
+	assert.NoError(importErr)
+	assert.Equal("api_example_com", imported[0].Profile.Name)
+	assert.Equal(global.HashName("api_example_com", "api-example.com"), imported[1].Profile.Name)
+
+	firstCredential, firstErr := credential.LoadFromProfile(imported[0].Profile.Name, testFactory)
+	assert.NoError(firstErr)
+	assert.Equal("first_user", firstCredential.Username)
+	assert.Equal("api.example.com", firstCredential.GetAttribute(hostKey))
+	secondCredential, secondErr := credential.LoadFromProfile(imported[1].Profile.Name, testFactory)
+	assert.NoError(secondErr)
+	assert.Equal("second_user", secondCredential.Username)
+	assert.Equal("api-example.com", secondCredential.GetAttribute(hostKey))
+}
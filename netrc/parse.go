@@ -0,0 +1,170 @@
+package netrc
+
+import (
+	"errors"
+	"strings"
+)
+
+const kindPreamble = "preamble"
+const kindMachine = "machine"
+const kindDefault = "default"
+const kindMacdef = "macdef"
+
+const keywordLogin = "login"
+const keywordPassword = "password"
+const keywordAccount = "account"
+
+// tokenizer reads the whitespace separated tokens of a netrc file, keeping track of where each one starts.
+type tokenizer struct {
+	contents string
+	position int
+}
+
+/*
+parse splits the contents of a netrc file into entries, which joined together give back contents. Values may be quoted
+with double quotes, in which a backslash escapes the next character, and a # outside of a token starts a comment that
+runs to the end of the line.
+*/
+func parse(contents string) ([]*entry, error) {
+	myTokenizer := &tokenizer{contents: contents}
+	current := &entry{kind: kindPreamble, spans: make(map[string][2]int)}
+	entries := []*entry{current}
+	start := 0
+
+	for {
+		tokenStart, token, tokenErr := myTokenizer.next()
+
+		if tokenErr != nil {
+			return nil, tokenErr
+		}
+
+		if token == "" {
+			break
+		}
+
+		switch token {
+		case kindMachine, kindDefault, kindMacdef:
+			current.raw = contents[start:tokenStart]
+			current = &entry{kind: token, spans: make(map[string][2]int)}
+			entries = append(entries, current)
+			start = tokenStart
+		}
+
+		var valueStart int
+
+		switch token {
+		case kindMachine:
+			valueStart, current.machine, tokenErr = myTokenizer.value()
+		case keywordLogin:
+			valueStart, current.login, tokenErr = myTokenizer.value()
+		case keywordPassword:
+			valueStart, current.password, tokenErr = myTokenizer.value()
+		case keywordAccount:
+			valueStart, current.account, tokenErr = myTokenizer.value()
+		case kindMacdef:
+			_, current.machine, tokenErr = myTokenizer.value()
+			myTokenizer.skipMacro()
+		}
+
+		switch token {
+		case kindMachine, keywordLogin, keywordPassword, keywordAccount:
+			current.spans[token] = [2]int{valueStart - start, myTokenizer.position - start}
+		}
+
+		if tokenErr != nil {
+			return nil, tokenErr
+		}
+	}
+
+	current.raw = contents[start:]
+	return entries, nil
+}
+
+// next returns the next token and where it starts, or a blank token at the end of the file.
+func (thisTokenizer *tokenizer) next() (int, string, error) {
+	contents := thisTokenizer.contents
+
+	for thisTokenizer.position < len(contents) {
+		character := contents[thisTokenizer.position]
+
+		if character == '#' {
+			if newline := strings.IndexByte(contents[thisTokenizer.position:], '\n'); newline >= 0 {
+				thisTokenizer.position += newline
+			} else {
+				thisTokenizer.position = len(contents)
+			}
+		} else if !isSpace(character) {
+			break
+		}
+
+		thisTokenizer.position++
+	}
+
+	start := thisTokenizer.position
+
+	if start >= len(contents) {
+		return start, "", nil
+	}
+
+	if contents[start] != '"' {
+		for thisTokenizer.position < len(contents) && !isSpace(contents[thisTokenizer.position]) {
+			thisTokenizer.position++
+		}
+
+		return start, contents[start:thisTokenizer.position], nil
+	}
+
+	var token strings.Builder
+
+	for thisTokenizer.position++; thisTokenizer.position < len(contents); thisTokenizer.position++ {
+		character := contents[thisTokenizer.position]
+
+		if character == '\\' && thisTokenizer.position+1 < len(contents) {
+			thisTokenizer.position++
+			character = contents[thisTokenizer.position]
+		} else if character == '"' {
+			thisTokenizer.position++
+			return start, token.String(), nil
+		}
+
+		token.WriteByte(character)
+	}
+
+	return start, "", errors.New(ERR_QUOTE_NOT_CLOSED)
+}
+
+// value returns the token following a keyword, which must be there, and where it starts.
+func (thisTokenizer *tokenizer) value() (int, string, error) {
+	start, token, tokenErr := thisTokenizer.next()
+
+	if tokenErr == nil && token == "" {
+		return start, "", errors.New(ERR_UNEXPECTED_END)
+	}
+
+	return start, token, tokenErr
+}
+
+// skipMacro moves past the body of a macdef, which starts on the next line and ends at a blank line.
+func (thisTokenizer *tokenizer) skipMacro() {
+	remaining := thisTokenizer.contents[thisTokenizer.position:]
+
+	if end := strings.Index(remaining, "\n\n"); end >= 0 {
+		thisTokenizer.position += end + 1
+	} else {
+		thisTokenizer.position = len(thisTokenizer.contents)
+	}
+}
+
+func isSpace(character byte) bool {
+	return character == ' ' || character == '\t' || character == '\n' || character == '\r'
+}
+
+// quote returns value as a netrc token, quoting it if it is blank or contains whitespace, quotes or a #.
+func quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\r\n\"\\#") {
+		return value
+	}
+
+	replacer := strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+	return "\"" + replacer.Replace(value) + "\""
+}
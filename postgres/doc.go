@@ -0,0 +1,27 @@
+/*
+Copyright (c) 2020 engi.fyi Contributors, All Rights Reserved.
+
+Licensed under the MIT License (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	https://engi.fyi/mit-license/
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package postgres builds PostgreSQL connection strings from go-credentials profiles, and imports and exports the
+~/.pgpass files read by psql and libpq.
+
+A profile's Username and Password are the database user and password, and the host, port and dbname attributes say
+where to connect, along with any of the libpq parameters in ConnectionParameters, such as sslmode:
+
+	connectionUrl, urlErr := postgres.LoadURL("reporting", myFactory)
+	db, openErr := sql.Open("postgres", connectionUrl)
+*/
+package postgres
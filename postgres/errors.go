@@ -0,0 +1,3 @@
+package postgres
+
+const ERR_INVALID_PGPASS_LINE = "sorry a line of the pgpass file does not have the five fields hostname:port:database:username:password"
@@ -0,0 +1,276 @@
+package postgres
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/credential"
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+	"github.com/engi-fyi/go-credentials/serializer"
+)
+
+const pgpassEnvironmentKey = "PGPASSFILE"
+const pgpassWildcard = "*"
+
+// pgpassEntry is a hostname:port:database:username:password line of a pgpass file.
+type pgpassEntry struct {
+	host     string
+	port     string
+	database string
+	username string
+	password string
+}
+
+/*
+DefaultPgpassFile returns the pgpass file used by libpq: the file named by the PGPASSFILE environment variable if it is
+set, otherwise ~/.pgpass.
+*/
+func DefaultPgpassFile() (string, error) {
+	if fileName, ok := os.LookupEnv(pgpassEnvironmentKey); ok && fileName != "" {
+		return fileName, nil
+	}
+
+	homeDirectory, homeErr := os.UserHomeDir()
+
+	if homeErr != nil {
+		return "", homeErr
+	}
+
+	return filepath.Join(homeDirectory, ".pgpass"), nil
+}
+
+/*
+ProfileName returns the name of the profile that a pgpass line for host, port, database and username is imported as,
+which is the four joined together with any characters that cannot be used in a profile name replaced by underscores,
+and a wildcard written as any. For example, db.example.com:5432:app:reader is imported as
+db_example_com_5432_app_reader, unless that name is already used by another line (see ImportPgpass).
+*/
+func ProfileName(host string, port string, database string, username string) string {
+	parts := []string{host, port, database, username}
+
+	for i, part := range parts {
+		if part == pgpassWildcard || part == "" {
+			parts[i] = "any"
+		}
+	}
+
+	return global.SanitizeName(strings.Join(parts, "_"))
+}
+
+/*
+ImportPgpass saves every line of fileName as a profile and returns the saved Credentials. A new line is saved under
+ProfileName, or if another line already uses that name, under the name given by global.HashName for the line. The host,
+port and database are saved in the host, port and dbname attributes, unless they are wildcards. A profile that already
+exists is updated rather than replaced, so its other attributes, such as sslmode, are kept. Lines with a wildcard
+username are skipped, as a Credential must have a username. As libpq uses the first line that matches a connection,
+later lines for the same host, port, database and username are skipped too.
+*/
+func ImportPgpass(sourceFactory *factory.Factory, fileName string) ([]*credential.Credential, error) {
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	var imported []*credential.Credential
+	var importedEntries []*pgpassEntry
+
+	for _, line := range strings.Split(string(contents), "\n") {
+		myEntry, parseErr := parsePgpassLine(line)
+
+		if parseErr != nil {
+			sourceFactory.Log.Error().Err(parseErr).Str("file", fileName).Msg("Unable to parse pgpass file.")
+			return imported, parseErr
+		}
+
+		if myEntry == nil || myEntry.username == pgpassWildcard || myEntry.password == "" || myEntry.isIn(importedEntries) {
+			continue
+		}
+
+		myCredential, importErr := importPgpassEntry(sourceFactory, myEntry)
+
+		if importErr != nil {
+			return imported, importErr
+		}
+
+		imported = append(imported, myCredential)
+		importedEntries = append(importedEntries, myEntry)
+	}
+
+	return imported, nil
+}
+
+/*
+importPgpassEntry saves myEntry in the profile for its host, port, database and username. As different lines can give
+the same profile name, the stored username and host, port and dbname attributes must also match, and if the name is
+taken by another line, the name with a hash of the line added is used instead.
+*/
+func importPgpassEntry(sourceFactory *factory.Factory, myEntry *pgpassEntry) (*credential.Credential, error) {
+	profileName := ProfileName(myEntry.host, myEntry.port, myEntry.database, myEntry.username)
+	hashedName := global.HashName(profileName, strings.Join([]string{myEntry.host, myEntry.port, myEntry.database, myEntry.username}, ":"))
+	myCredential, freeName, loadErr := credential.LoadMatchingProfile(sourceFactory, []string{profileName, hashedName},
+		func(storedCredential *credential.Credential) bool {
+			return storedCredential.Username == myEntry.username &&
+				wildcardIfBlank(storedCredential.GetAttribute(HOST_KEY)) == wildcardIfBlank(myEntry.host) &&
+				wildcardIfBlank(storedCredential.GetAttribute(PORT_KEY)) == wildcardIfBlank(myEntry.port) &&
+				wildcardIfBlank(storedCredential.GetAttribute(DBNAME_KEY)) == wildcardIfBlank(myEntry.database)
+		})
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	if myCredential == nil {
+		myCredential, loadErr = credential.NewProfile(freeName, sourceFactory, myEntry.username, myEntry.password)
+
+		if loadErr != nil {
+			return nil, loadErr
+		}
+	}
+
+	myCredential.Username, myCredential.Password = myEntry.username, myEntry.password
+	attributes := [][2]string{{HOST_KEY, myEntry.host}, {PORT_KEY, myEntry.port}, {DBNAME_KEY, myEntry.database}}
+
+	for _, attribute := range attributes {
+		if attribute[1] == pgpassWildcard {
+			continue
+		}
+
+		if setErr := myCredential.SetAttribute(attribute[0], attribute[1]); setErr != nil {
+			return nil, setErr
+		}
+	}
+
+	return myCredential, myCredential.Save()
+}
+
+/*
+ExportPgpass writes each of profileNames to fileName as a pgpass line, using a wildcard for any of host, port and dbname
+that the profile does not have. A line already in the file for the same host, port, database and username is replaced,
+and other lines are added to the end. Comments and every other line are kept as they were. The file is created if it
+does not exist, and is always left with 0600 permissions, as libpq ignores a pgpass file that others can read. The new
+file is written alongside the old one and renamed over it (see serializer.WriteFileAtomic), so it is never left half
+written.
+*/
+func ExportPgpass(sourceFactory *factory.Factory, fileName string, profileNames ...string) error {
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return readErr
+	}
+
+	var lines []string
+
+	if len(contents) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	}
+
+	for _, profileName := range profileNames {
+		myCredential, loadErr := credential.LoadFromProfile(profileName, sourceFactory)
+
+		if loadErr != nil {
+			return loadErr
+		}
+
+		exported := &pgpassEntry{
+			host:     wildcardIfBlank(myCredential.GetAttribute(HOST_KEY)),
+			port:     wildcardIfBlank(myCredential.GetAttribute(PORT_KEY)),
+			database: wildcardIfBlank(myCredential.GetAttribute(DBNAME_KEY)),
+			username: myCredential.Username,
+			password: myCredential.Password,
+		}
+
+		replaced := false
+
+		for i, line := range lines {
+			if existing, parseErr := parsePgpassLine(line); parseErr == nil && existing != nil && existing.sameConnection(exported) {
+				lines[i], replaced = exported.String(), true
+				break
+			}
+		}
+
+		if !replaced {
+			lines = append(lines, exported.String())
+		}
+	}
+
+	return serializer.WriteFileAtomic(fileName, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
+
+/*
+parsePgpassLine parses a hostname:port:database:username:password line, in which a backslash escapes a colon or
+backslash. Blank lines and comments return nil.
+*/
+func parsePgpassLine(line string) (*pgpassEntry, error) {
+	line = strings.TrimSuffix(line, "\r")
+
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return nil, nil
+	}
+
+	var fields []string
+	var field strings.Builder
+
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) {
+			i++
+			field.WriteByte(line[i])
+		} else if line[i] == ':' && len(fields) < 4 {
+			fields = append(fields, field.String())
+			field.Reset()
+		} else {
+			field.WriteByte(line[i])
+		}
+	}
+
+	fields = append(fields, field.String())
+
+	if len(fields) != 5 {
+		return nil, errors.New(ERR_INVALID_PGPASS_LINE)
+	}
+
+	return &pgpassEntry{host: fields[0], port: fields[1], database: fields[2], username: fields[3], password: fields[4]}, nil
+}
+
+// String returns the entry as a pgpass line, escaping colons and backslashes.
+func (thisEntry *pgpassEntry) String() string {
+	replacer := strings.NewReplacer("\\", "\\\\", ":", "\\:")
+	fields := []string{thisEntry.host, thisEntry.port, thisEntry.database, thisEntry.username, thisEntry.password}
+
+	for i, field := range fields {
+		if field != pgpassWildcard {
+			fields[i] = replacer.Replace(field)
+		}
+	}
+
+	return strings.Join(fields, ":")
+}
+
+func (thisEntry *pgpassEntry) sameConnection(other *pgpassEntry) bool {
+	return thisEntry.host == other.host && thisEntry.port == other.port && thisEntry.database == other.database &&
+		thisEntry.username == other.username
+}
+
+func (thisEntry *pgpassEntry) isIn(entries []*pgpassEntry) bool {
+	for _, other := range entries {
+		if thisEntry.sameConnection(other) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func wildcardIfBlank(value string) string {
+	if value == "" {
+		return pgpassWildcard
+	}
+
+	return value
+}
@@ -0,0 +1,204 @@
+package profile
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+/*
+MarkClean records the current attributes as the ones that have been saved, so that IsDirty and GetChanges only report
+changes made from now on. Credential calls this after the Profile has been loaded or saved.
+*/
+func (thisProfile *Profile) MarkClean() {
+	thisProfile.savedAttributes = copyAttributes(thisProfile.attributes)
+}
+
+/*
+IsDirty returns true if any attribute has been added, modified or deleted since the Profile was last marked clean. A
+Profile that has never been marked clean is dirty as soon as it has any attributes.
+*/
+func (thisProfile *Profile) IsDirty() bool {
+	return len(thisProfile.GetChanges()) > 0
+}
+
+/*
+GetChanges lists every attribute that has been added, modified or deleted since the Profile was last marked clean,
+sorted by section and then key. Sections that are empty are ignored, as they are not stored.
+*/
+func (thisProfile *Profile) GetChanges() []AttributeChange {
+	return DiffAttributes(thisProfile.savedAttributes, thisProfile.attributes)
+}
+
+/*
+DiffAttributes lists the changes needed to turn the attributes in before into the attributes in after, sorted by
+section and then key.
+*/
+func DiffAttributes(before map[string]map[string]string, after map[string]map[string]string) []AttributeChange {
+	changes := make([]AttributeChange, 0)
+
+	for sectionName, values := range after {
+		for key, newValue := range values {
+			oldValue, existed := before[sectionName][key]
+
+			if !existed {
+				changes = append(changes, AttributeChange{Section: sectionName, Key: key, Type: global.CHANGE_TYPE_ADDED, OldValue: "", NewValue: newValue})
+			} else if oldValue != newValue {
+				changes = append(changes, AttributeChange{Section: sectionName, Key: key, Type: global.CHANGE_TYPE_MODIFIED, OldValue: oldValue, NewValue: newValue})
+			}
+		}
+	}
+
+	for sectionName, values := range before {
+		for key, oldValue := range values {
+			if _, exists := after[sectionName][key]; !exists {
+				changes = append(changes, AttributeChange{Section: sectionName, Key: key, Type: global.CHANGE_TYPE_DELETED, OldValue: oldValue, NewValue: ""})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i int, j int) bool {
+		if changes[i].Section != changes[j].Section {
+			return changes[i].Section < changes[j].Section
+		}
+
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+func copyAttributes(attributes map[string]map[string]string) map[string]map[string]string {
+	copied := make(map[string]map[string]string)
+
+	for sectionName, values := range attributes {
+		copied[sectionName] = make(map[string]string)
+
+		for key, value := range values {
+			copied[sectionName][key] = value
+		}
+	}
+
+	return copied
+}
+
+// GetSavedAttributes returns a copy of the attributes as they were when the Profile was last marked clean.
+func (thisProfile *Profile) GetSavedAttributes() map[string]map[string]string {
+	return copyAttributes(thisProfile.savedAttributes)
+}
+
+/*
+MergeAttributes performs a three-way merge of attributes. base is what was originally loaded, mine has the local changes
+and theirs has the changes made elsewhere since base was loaded. Where only one side changed an attribute, that change
+is kept. Where both sides changed an attribute differently, the value from mine is kept and an AttributeConflict is
+returned so the caller can decide. The conflicts are sorted by section and then key.
+*/
+func MergeAttributes(base map[string]map[string]string, mine map[string]map[string]string, theirs map[string]map[string]string) (map[string]map[string]string, []AttributeConflict) {
+	merged := make(map[string]map[string]string)
+	conflicts := make([]AttributeConflict, 0)
+	keys := make(map[string]map[string]bool)
+
+	for _, attributes := range []map[string]map[string]string{base, mine, theirs} {
+		for sectionName, values := range attributes {
+			if _, exists := keys[sectionName]; !exists {
+				keys[sectionName] = make(map[string]bool)
+			}
+
+			for key := range values {
+				keys[sectionName][key] = true
+			}
+		}
+	}
+
+	for sectionName, sectionKeys := range keys {
+		merged[sectionName] = make(map[string]string)
+
+		for key := range sectionKeys {
+			baseValue, inBase := base[sectionName][key]
+			myValue, inMine := mine[sectionName][key]
+			theirValue, inTheirs := theirs[sectionName][key]
+			resultValue, inResult := myValue, inMine
+
+			if inMine == inBase && myValue == baseValue {
+				resultValue, inResult = theirValue, inTheirs
+			} else if !(inTheirs == inBase && theirValue == baseValue) && !(inTheirs == inMine && theirValue == myValue) {
+				conflicts = append(conflicts, AttributeConflict{
+					Section:    sectionName,
+					Key:        key,
+					BaseValue:  baseValue,
+					MyValue:    myValue,
+					TheirValue: theirValue,
+				})
+			}
+
+			if inResult {
+				merged[sectionName][key] = resultValue
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i int, j int) bool {
+		if conflicts[i].Section != conflicts[j].Section {
+			return conflicts[i].Section < conflicts[j].Section
+		}
+
+		return conflicts[i].Key < conflicts[j].Key
+	})
+
+	return merged, conflicts
+}
+
+// Diff lists the attributes that were added, modified or deleted going from one Profile to another.
+func Diff(fromProfile *Profile, toProfile *Profile) []AttributeChange {
+	return DiffAttributes(fromProfile.GetAllAttributes(), toProfile.GetAllAttributes())
+}
+
+/*
+RenderChanges formats changes as text, grouped under a header for each section. Each line starts with + for an added
+attribute, - for a deleted attribute or ~ for a modified attribute, for example:
+
+	[first_section]
+	+ new_key = a value
+	- old_key = a value
+	~ changed_key = old value -> new value
+
+Changes with a blank section, such as username and password changes from a Credential, are grouped under [credential].
+If there are no changes, the line "no changes" is returned.
+*/
+func RenderChanges(changes []AttributeChange) string {
+	if len(changes) == 0 {
+		return "no changes\n"
+	}
+
+	var builder strings.Builder
+	currentSection := ""
+
+	for i, change := range changes {
+		sectionName := change.Section
+
+		if sectionName == "" {
+			sectionName = "credential"
+		}
+
+		if i == 0 || sectionName != currentSection {
+			if i > 0 {
+				builder.WriteString("\n")
+			}
+
+			builder.WriteString("[" + sectionName + "]\n")
+			currentSection = sectionName
+		}
+
+		switch change.Type {
+		case global.CHANGE_TYPE_ADDED:
+			builder.WriteString("+ " + change.Key + " = " + change.NewValue + "\n")
+		case global.CHANGE_TYPE_DELETED:
+			builder.WriteString("- " + change.Key + " = " + change.OldValue + "\n")
+		default:
+			builder.WriteString("~ " + change.Key + " = " + change.OldValue + " -> " + change.NewValue + "\n")
+		}
+	}
+
+	return builder.String()
+}
@@ -1,6 +1,9 @@
 package profile
 
 const ERR_PROFILE_NAME_MUST_MATCH_REGEX = "sorry the profile name must only include letters, numbers and underscores [0-9A-Za-z_]"
+const ERR_AWS_PROFILE_NAME_MUST_MATCH_REGEX = "sorry the profile name must only include letters, numbers, underscores, hyphens and full stops [0-9A-Za-z_.-]"
+const ERR_PROFILE_NAME_RESERVED = "sorry that profile name is reserved by go-credentials"
+const ERR_SECTION_NAME_RESERVED = "sorry that section name is reserved by go-credentials"
 const ERR_PROFILE_DID_NOT_EXIST = "the config file did not exist and a profile has not been loaded"
 const ERR_DELETED_ATTRIBUTE_NOT_EXIST = "the attribute you have attempted to delete does not exist"
 const ERR_MUST_MATCH_REGEX = "sorry the section name and value must only include letters, numbers and underscores [0-9A-Za-z_]"
@@ -9,14 +9,27 @@ import (
 
 /*
 New is responsible for constructing a new, blank profile to be used by a Credential. It is important to note, that
-this function does not save a profile, and this needs to be done using the Save() function.
+this function does not save a profile, and this needs to be done using the Save() function. The name
+global.FORMAT_SECTION_NAME is reserved for the format version of the credentials file, and cannot be used. With the aws
+output type, profile names may also include hyphens and full stops, as AWS profiles such as prod-admin often do.
 */
 func New(profileName string, sourceFactory *factory.Factory) (*Profile, error) {
 	keyRegex := regexp.MustCompile(global.REGEX_KEY_NAME)
+	nameErr := ERR_PROFILE_NAME_MUST_MATCH_REGEX
+
+	if sourceFactory.OutputType == global.OUTPUT_TYPE_AWS {
+		keyRegex = regexp.MustCompile(global.REGEX_AWS_PROFILE_NAME)
+		nameErr = ERR_AWS_PROFILE_NAME_MUST_MATCH_REGEX
+	}
 
 	if !keyRegex.MatchString(profileName) {
-		sourceFactory.Log.Error().Msg(ERR_PROFILE_NAME_MUST_MATCH_REGEX)
-		return nil, errors.New(ERR_PROFILE_NAME_MUST_MATCH_REGEX)
+		sourceFactory.Log.Error().Msg(nameErr)
+		return nil, errors.New(nameErr)
+	}
+
+	if profileName == global.FORMAT_SECTION_NAME {
+		sourceFactory.Log.Error().Msg(ERR_PROFILE_NAME_RESERVED)
+		return nil, errors.New(ERR_PROFILE_NAME_RESERVED)
 	}
 
 	newProfile := Profile{
@@ -32,7 +45,8 @@ func New(profileName string, sourceFactory *factory.Factory) (*Profile, error) {
 
 /*
 SetAttribute is responsible for setting an attribute against a section name. If the section name is blank, the attribute
-will be stored without a section.
+will be stored without a section. The section name global.FORMAT_SECTION_NAME is reserved for the format version of the
+credentials file, and cannot be used.
 
 Example: With Section
 	myProfile.SetAttribute("a_section", "a_key", "a_value")
@@ -61,6 +75,11 @@ func (thisProfile *Profile) SetAttribute(sectionName string, key string, value s
 		return errors.New(ERR_MUST_MATCH_REGEX)
 	}
 
+	if sectionName == global.FORMAT_SECTION_NAME {
+		thisProfile.Factory.Log.Error().Msg(ERR_SECTION_NAME_RESERVED)
+		return errors.New(ERR_SECTION_NAME_RESERVED)
+	}
+
 	if _, ok := thisProfile.attributes[sectionName]; !ok {
 		thisProfile.attributes[sectionName] = make(map[string]string)
 	}
@@ -8,6 +8,27 @@ type Profile struct {
 	Name               string
 	ConfigFileLocation string
 	attributes         map[string]map[string]string
+	savedAttributes    map[string]map[string]string
 	Initialized        bool
 	Factory            *factory.Factory
 }
+
+// AttributeChange describes one attribute that has been added, modified or deleted since a Profile was last marked clean.
+// Type is one of global.CHANGE_TYPE_ADDED, global.CHANGE_TYPE_MODIFIED or global.CHANGE_TYPE_DELETED.
+type AttributeChange struct {
+	Section  string
+	Key      string
+	Type     string
+	OldValue string
+	NewValue string
+}
+
+// AttributeConflict describes an attribute that was changed differently on both sides of a MergeAttributes call. A blank
+// value means the attribute did not exist on that side.
+type AttributeConflict struct {
+	Section    string
+	Key        string
+	BaseValue  string
+	MyValue    string
+	TheirValue string
+}
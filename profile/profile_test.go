@@ -43,6 +43,28 @@ func TestProfileBlankName(t *testing.T) {
 	assert.Nil(testProfile)
 }
 
+func TestProfileReservedName(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing the creation of a new profile with the reserved format section name.")
+	testFactory, factoryErr := factory.New(global.TEST_VAR_APPLICATION_NAME)
+	assert.NoError(factoryErr)
+	testProfile, newErr := New(global.FORMAT_SECTION_NAME, testFactory)
+	assert.EqualError(newErr, ERR_PROFILE_NAME_RESERVED)
+	assert.Nil(testProfile)
+}
+
+func TestProfileReservedSectionName(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing setting an attribute in the reserved format section.")
+	testFactory, factoryErr := factory.New(global.TEST_VAR_APPLICATION_NAME)
+	assert.NoError(factoryErr)
+	testProfile, newErr := New(global.DEFAULT_PROFILE_NAME, testFactory)
+	assert.NoError(newErr)
+	setErr := testProfile.SetAttribute(global.FORMAT_SECTION_NAME, global.FORMAT_VERSION_LABEL, global.FORMAT_VERSION)
+	assert.EqualError(setErr, ERR_SECTION_NAME_RESERVED)
+	assert.Equal("", testProfile.GetAttribute(global.FORMAT_SECTION_NAME, global.FORMAT_VERSION_LABEL))
+}
+
 func TestProfileAttribute(t *testing.T) {
 	assert, log := global.InitTest(t)
 	log.Info().Msg("Testing the single attributes on a profile.")
@@ -122,3 +144,129 @@ func TestProfileSectionAttributes(t *testing.T) {
 
 	os.RemoveAll(testFactory.ParentDirectory)
 }
+
+func TestProfileChanges(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing tracking the changes made to a profile.")
+	testFactory, factoryErr := factory.New(global.TEST_VAR_APPLICATION_NAME)
+	assert.NoError(factoryErr)
+	testProfile, newErr := New(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory)
+	assert.NoError(newErr)
+	assert.False(testProfile.IsDirty())
+
+	assert.NoError(testProfile.SetAttribute(global.TEST_VAR_SECOND_SECTION_KEY, global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.NoError(testProfile.SetAttribute(global.TEST_VAR_FIRST_SECTION_KEY, global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.NoError(testProfile.SetAttribute(global.TEST_VAR_FIRST_SECTION_KEY, "removed_key", global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.True(testProfile.IsDirty())
+	assert.Len(testProfile.GetChanges(), 3)
+
+	testProfile.MarkClean()
+	assert.False(testProfile.IsDirty())
+	assert.Empty(testProfile.GetChanges())
+
+	assert.NoError(testProfile.SetAttribute(global.TEST_VAR_SECOND_SECTION_KEY, global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED))
+	assert.NoError(testProfile.DeleteAttribute(global.TEST_VAR_FIRST_SECTION_KEY, "removed_key"))
+	assert.NoError(testProfile.SetAttribute("", "added_key", global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.True(testProfile.IsDirty())
+	assert.Equal([]AttributeChange{
+		{Section: global.NO_SECTION_KEY, Key: "added_key", Type: global.CHANGE_TYPE_ADDED, OldValue: "", NewValue: global.TEST_VAR_ATTRIBUTE_VALUE},
+		{Section: global.TEST_VAR_FIRST_SECTION_KEY, Key: "removed_key", Type: global.CHANGE_TYPE_DELETED, OldValue: global.TEST_VAR_ATTRIBUTE_VALUE, NewValue: ""},
+		{Section: global.TEST_VAR_SECOND_SECTION_KEY, Key: global.TEST_VAR_ATTRIBUTE_NAME_LABEL, Type: global.CHANGE_TYPE_MODIFIED, OldValue: global.TEST_VAR_ATTRIBUTE_VALUE, NewValue: global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED},
+	}, testProfile.GetChanges())
+
+	assert.NoError(testProfile.SetAttribute(global.TEST_VAR_SECOND_SECTION_KEY, global.TEST_VAR_ATTRIBUTE_NAME_LABEL, global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.NoError(testProfile.SetAttribute(global.TEST_VAR_FIRST_SECTION_KEY, "removed_key", global.TEST_VAR_ATTRIBUTE_VALUE))
+	assert.NoError(testProfile.DeleteAttribute(global.NO_SECTION_KEY, "added_key"))
+	assert.False(testProfile.IsDirty())
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
+func TestProfileMergeAttributes(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing three-way merging of attributes.")
+	base := map[string]map[string]string{
+		global.TEST_VAR_FIRST_SECTION_KEY: {
+			"unchanged":     "base",
+			"mine_changed":  "base",
+			"their_changed": "base",
+			"both_same":     "base",
+			"both_changed":  "base",
+			"mine_deleted":  "base",
+		},
+	}
+	mine := map[string]map[string]string{
+		global.TEST_VAR_FIRST_SECTION_KEY: {
+			"unchanged":     "base",
+			"mine_changed":  "mine",
+			"their_changed": "base",
+			"both_same":     "same",
+			"both_changed":  "mine",
+			"mine_added":    "mine",
+		},
+	}
+	theirs := map[string]map[string]string{
+		global.TEST_VAR_FIRST_SECTION_KEY: {
+			"unchanged":     "base",
+			"mine_changed":  "base",
+			"their_changed": "theirs",
+			"both_same":     "same",
+			"both_changed":  "theirs",
+			"mine_deleted":  "base",
+		},
+		global.TEST_VAR_SECOND_SECTION_KEY: {
+			"their_added": "theirs",
+		},
+	}
+
+	merged, conflicts := MergeAttributes(base, mine, theirs)
+	assert.Equal(map[string]map[string]string{
+		global.TEST_VAR_FIRST_SECTION_KEY: {
+			"unchanged":     "base",
+			"mine_changed":  "mine",
+			"their_changed": "theirs",
+			"both_same":     "same",
+			"both_changed":  "mine",
+			"mine_added":    "mine",
+		},
+		global.TEST_VAR_SECOND_SECTION_KEY: {
+			"their_added": "theirs",
+		},
+	}, merged)
+	assert.Equal([]AttributeConflict{{
+		Section:    global.TEST_VAR_FIRST_SECTION_KEY,
+		Key:        "both_changed",
+		BaseValue:  "base",
+		MyValue:    "mine",
+		TheirValue: "theirs",
+	}}, conflicts)
+}
+
+func TestProfileDiff(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing comparing two profiles and rendering the changes.")
+	testFactory, factoryErr := factory.New(global.TEST_VAR_APPLICATION_NAME)
+	assert.NoError(factoryErr)
+	fromProfile, newErr := New(global.TEST_VAR_FIRST_PROFILE_LABEL, testFactory)
+	assert.NoError(newErr)
+	toProfile, newErr := New(global.TEST_VAR_SECOND_PROFILE_LABEL, testFactory)
+	assert.NoError(newErr)
+
+	assert.Equal("no changes\n", RenderChanges(Diff(fromProfile, toProfile)))
+
+	assert.NoError(fromProfile.SetAttribute("", "removed_key", "old"))
+	assert.NoError(fromProfile.SetAttribute(global.TEST_VAR_FIRST_SECTION_KEY, "changed_key", "old"))
+	assert.NoError(toProfile.SetAttribute(global.TEST_VAR_FIRST_SECTION_KEY, "changed_key", "new"))
+	assert.NoError(toProfile.SetAttribute(global.TEST_VAR_FIRST_SECTION_KEY, "added_key", "new"))
+
+	changes := Diff(fromProfile, toProfile)
+	assert.Len(changes, 3)
+	assert.Equal("[DEFAULT]\n"+
+		"- removed_key = old\n"+
+		"\n"+
+		"[first_section]\n"+
+		"+ added_key = new\n"+
+		"~ changed_key = old -> new\n", RenderChanges(changes))
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
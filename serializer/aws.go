@@ -0,0 +1,315 @@
+package serializer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+const awsProfilePrefix = "profile "
+
+// awsCredentialKeys are the keys, besides the access key, that belong in the shared credentials file when they are new.
+var awsCredentialKeys = map[string]bool{
+	"aws_session_token":  true,
+	"aws_security_token": true,
+}
+
+/*
+GetAwsFiles returns the AWS shared credentials and config files used by the aws output type. These are the files named
+by the AWS_SHARED_CREDENTIALS_FILE and AWS_CONFIG_FILE variables in the Factory's environment (see
+factory.SetEnvironment), or ~/.aws/credentials and ~/.aws/config, as with the AWS CLI and SDKs.
+*/
+func GetAwsFiles(sourceFactory *factory.Factory) (string, string, error) {
+	environment := sourceFactory.GetEnvironment()
+	credentialFile, configFile := environment[global.AWS_CREDENTIALS_FILE_ENVIRONMENT_KEY], environment[global.AWS_CONFIG_FILE_ENVIRONMENT_KEY]
+
+	if credentialFile == "" || configFile == "" {
+		homeDirectory, homeErr := os.UserHomeDir()
+
+		if homeErr != nil {
+			return "", "", homeErr
+		}
+
+		if credentialFile == "" {
+			credentialFile = filepath.Join(homeDirectory, ".aws", "credentials")
+		}
+
+		if configFile == "" {
+			configFile = filepath.Join(homeDirectory, ".aws", "config")
+		}
+	}
+
+	return credentialFile, configFile, nil
+}
+
+/*
+ToAws is responsible for serializing a Credential and Profile to the AWS shared credentials and config files (see
+GetAwsFiles), in the same layout as the AWS CLI. The username and password are the aws_access_key_id and
+aws_secret_access_key of the profile's section in the credentials file, whatever alternates are set on the Factory.
+Attributes without a section, such as region or source_profile, go in the profile's section of the config file, which
+is [default] for the default profile and [profile name] for every other profile. Attributes in a section are written as
+a nested setting of the same name, such as:
+
+	[profile dev]
+	s3 =
+	    max_concurrent_requests = 20
+
+Attributes that are already in the credentials file, such as aws_session_token, are kept there. Only the keys that have
+changed are rewritten, so comments, formatting, other profiles and settings this library does not use, such as
+[sso-session] sections, are left exactly as they were. Format versions are not recorded in the AWS files.
+*/
+func (thisSerializer *Serializer) ToAws(username string, password string, attributes map[string]map[string]string) error {
+	thisSerializer.Factory.Log.Info().Msg("Serializing credential and profile to AWS files.")
+	credentialFileName, configFileName, filesErr := GetAwsFiles(thisSerializer.Factory)
+
+	if filesErr != nil {
+		return filesErr
+	}
+
+	credentialFile, loadErr := loadAwsFile(credentialFileName)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	configFile, loadErr := loadAwsFile(configFileName)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	credentialSection := credentialFile.section(thisSerializer.ProfileName)
+
+	if credentialSection == nil {
+		credentialSection = credentialFile.addSection(thisSerializer.ProfileName)
+	}
+
+	configSection := configFile.section(thisSerializer.awsConfigSectionName(configFile))
+	values := make(map[string]string)
+
+	for key, value := range attributes[global.NO_SECTION_KEY] {
+		values[key] = value
+	}
+
+	values[global.AWS_ACCESS_KEY_ID_LABEL], values[global.AWS_SECRET_ACCESS_KEY_LABEL] = username, password
+
+	for _, key := range sortedKeys(values) {
+		inConfig := configSection.find(key) != nil
+		isAccessKey := key == global.AWS_ACCESS_KEY_ID_LABEL || key == global.AWS_SECRET_ACCESS_KEY_LABEL
+		toCredentials := isAccessKey || credentialSection.find(key) != nil || (awsCredentialKeys[key] && !inConfig)
+
+		if toCredentials {
+			credentialSection.set(key, values[key])
+		}
+
+		if inConfig || !toCredentials {
+			if configSection == nil {
+				configSection = configFile.addSection(thisSerializer.awsConfigSectionName(configFile))
+			}
+
+			configSection.set(key, values[key])
+		}
+	}
+
+	for _, sectionName := range sortedSectionNames(attributes) {
+		if sectionName != global.NO_SECTION_KEY && len(attributes[sectionName]) > 0 {
+			if configSection == nil {
+				configSection = configFile.addSection(thisSerializer.awsConfigSectionName(configFile))
+			}
+
+			configSection.setNested(sectionName, attributes[sectionName])
+		}
+	}
+
+	removeAwsKeys(credentialSection, values, nil)
+	removeAwsKeys(configSection, values, attributes)
+
+	if saveErr := credentialFile.save(credentialFileName); saveErr != nil {
+		return saveErr
+	}
+
+	if configSection == nil {
+		return nil
+	}
+
+	return configFile.save(configFileName)
+}
+
+// removeAwsKeys deletes the keys of mySection that are not in values or, for nested settings, a section of attributes.
+func removeAwsKeys(mySection *awsSection, values map[string]string, attributes map[string]map[string]string) {
+	if mySection == nil {
+		return
+	}
+
+	keyRegex := regexp.MustCompile(global.REGEX_KEY_NAME)
+	sectionValues, nested := mySection.values()
+
+	for key := range sectionValues {
+		if _, exists := values[key]; !exists && keyRegex.MatchString(key) {
+			mySection.delete(key)
+		}
+	}
+
+	for key := range nested {
+		if _, exists := attributes[key]; (!exists || len(attributes[key]) == 0) && keyRegex.MatchString(key) {
+			mySection.delete(key)
+		}
+	}
+}
+
+/*
+FromAws is responsible for deserializing a Credential and Profile from the AWS shared credentials and config files, see
+ToAws. Where a key is in both files, the credentials file is used, as with the AWS CLI. Keys that are not valid
+attribute names are skipped, but are kept when the profile is saved.
+*/
+func (thisSerializer *Serializer) FromAws() (string, string, map[string]map[string]string, error) {
+	thisSerializer.Factory.Log.Info().Msg("Deserializing credential and profile from AWS files.")
+	attributes := make(map[string]map[string]string)
+	credentialFileName, configFileName, filesErr := GetAwsFiles(thisSerializer.Factory)
+
+	if filesErr != nil {
+		return "", "", attributes, filesErr
+	}
+
+	credentialFile, loadErr := loadAwsFile(credentialFileName)
+
+	if loadErr != nil {
+		return "", "", attributes, loadErr
+	}
+
+	configFile, loadErr := loadAwsFile(configFileName)
+
+	if loadErr != nil {
+		return "", "", attributes, loadErr
+	}
+
+	keyRegex := regexp.MustCompile(global.REGEX_KEY_NAME)
+	configValues, nested := configFile.section(thisSerializer.awsConfigSectionName(configFile)).values()
+	credentialValues, _ := credentialFile.section(thisSerializer.ProfileName).values()
+	values := make(map[string]string)
+
+	for _, sectionValues := range []map[string]string{configValues, credentialValues} {
+		for key, value := range sectionValues {
+			if keyRegex.MatchString(key) {
+				values[key] = value
+			}
+		}
+	}
+
+	for key, subValues := range nested {
+		if keyRegex.MatchString(key) {
+			attributes[key] = subValues
+		}
+	}
+
+	username, password := values[global.AWS_ACCESS_KEY_ID_LABEL], values[global.AWS_SECRET_ACCESS_KEY_LABEL]
+	delete(values, global.AWS_ACCESS_KEY_ID_LABEL)
+	delete(values, global.AWS_SECRET_ACCESS_KEY_LABEL)
+
+	if len(values) > 0 {
+		attributes[global.NO_SECTION_KEY] = values
+	}
+
+	return username, password, attributes, nil
+}
+
+// loadAwsCredential returns the access key of the profile, for saving its attributes on their own.
+func (thisSerializer *Serializer) loadAwsCredential() (string, string, error) {
+	username, password, _, loadErr := thisSerializer.FromAws()
+	return username, password, loadErr
+}
+
+// deleteAws removes the profile's sections from the AWS credentials and config files.
+func (thisSerializer *Serializer) deleteAws() error {
+	credentialFileName, configFileName, filesErr := GetAwsFiles(thisSerializer.Factory)
+
+	if filesErr != nil {
+		return filesErr
+	}
+
+	for _, fileName := range []string{credentialFileName, configFileName} {
+		if _, statErr := os.Stat(fileName); os.IsNotExist(statErr) {
+			continue
+		}
+
+		myFile, loadErr := loadAwsFile(fileName)
+
+		if loadErr != nil {
+			return loadErr
+		}
+
+		sectionName := thisSerializer.ProfileName
+
+		if fileName == configFileName {
+			sectionName = thisSerializer.awsConfigSectionName(myFile)
+		}
+
+		myFile.deleteSection(sectionName)
+
+		if saveErr := myFile.save(fileName); saveErr != nil {
+			return saveErr
+		}
+	}
+
+	return nil
+}
+
+/*
+awsConfigSectionName returns the name of the profile's section in the config file. The default profile may be written
+as either [default] or [profile default], so whichever is already in the file is used.
+*/
+func (thisSerializer *Serializer) awsConfigSectionName(configFile *awsFile) string {
+	if thisSerializer.ProfileName != global.DEFAULT_PROFILE_NAME {
+		return awsProfilePrefix + thisSerializer.ProfileName
+	}
+
+	if configFile.section(global.DEFAULT_PROFILE_NAME) == nil && configFile.section(awsProfilePrefix+global.DEFAULT_PROFILE_NAME) != nil {
+		return awsProfilePrefix + global.DEFAULT_PROFILE_NAME
+	}
+
+	return global.DEFAULT_PROFILE_NAME
+}
+
+/*
+listProfilesAws adds every profile in the AWS credentials file, and every [default] or [profile name] in the config.
+Profile names may include hyphens and full stops, as with the AWS CLI (see profile.New).
+*/
+func listProfilesAws(sourceFactory *factory.Factory, profiles map[string]bool) error {
+	credentialFileName, configFileName, filesErr := GetAwsFiles(sourceFactory)
+
+	if filesErr != nil {
+		return filesErr
+	}
+
+	nameRegex := regexp.MustCompile(global.REGEX_AWS_PROFILE_NAME)
+
+	for _, fileName := range []string{credentialFileName, configFileName} {
+		myFile, loadErr := loadAwsFile(fileName)
+
+		if loadErr != nil {
+			return loadErr
+		}
+
+		for _, mySection := range myFile.sections {
+			profileName := mySection.name
+
+			if fileName == configFileName && profileName != global.DEFAULT_PROFILE_NAME {
+				if !strings.HasPrefix(profileName, awsProfilePrefix) {
+					continue
+				}
+
+				profileName = strings.TrimSpace(strings.TrimPrefix(profileName, awsProfilePrefix))
+			}
+
+			if nameRegex.MatchString(profileName) {
+				profiles[profileName] = true
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package serializer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+const testAwsCredentials = `# managed by hand
+[default]
+aws_access_key_id = AKIADEFAULT
+aws_secret_access_key = default_secret
+
+[dev]
+aws_access_key_id=AKIADEV
+aws_secret_access_key=dev_secret
+aws_session_token=dev_token
+`
+
+const testAwsConfig = `[default]
+region = us-east-1
+
+[profile dev]
+# the role used for dev
+region = eu-west-1
+source_profile = default
+s3 =
+    max_concurrent_requests = 20
+    addressing_style = path
+cli-pager =
+
+[sso-session corp]
+sso_region = us-east-1
+`
+
+func createTestAws(t *testing.T) (*factory.Factory, string, string) {
+	testFactory, factoryErr := factory.New(global.TEST_VAR_APPLICATION_NAME)
+
+	if factoryErr != nil {
+		t.Fatal(factoryErr)
+	}
+
+	awsDirectory := filepath.Join(testFactory.ParentDirectory, "aws")
+	credentialFile, configFile := filepath.Join(awsDirectory, "credentials"), filepath.Join(awsDirectory, "config")
+	testFactory.SetEnvironment(map[string]string{
+		global.AWS_CREDENTIALS_FILE_ENVIRONMENT_KEY: credentialFile,
+		global.AWS_CONFIG_FILE_ENVIRONMENT_KEY:      configFile,
+	})
+
+	if outputErr := testFactory.SetOutputType(global.OUTPUT_TYPE_AWS); outputErr != nil {
+		t.Fatal(outputErr)
+	}
+
+	return testFactory, credentialFile, configFile
+}
+
+func TestFromAws(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing loading profiles from the AWS shared credentials and config files.")
+	testFactory, credentialFile, configFile := createTestAws(t)
+	assert.NoError(os.MkdirAll(filepath.Dir(credentialFile), 0700))
+	assert.NoError(ioutil.WriteFile(credentialFile, []byte(testAwsCredentials), 0600))
+	assert.NoError(ioutil.WriteFile(configFile, []byte(testAwsConfig), 0600))
+
+	profiles, listErr := ListProfiles(testFactory)
+	assert.NoError(listErr)
+	assert.Equal([]string{global.DEFAULT_PROFILE_NAME, "dev"}, profiles)
+
+	username, password, attributes, deserializeErr := New(testFactory, "dev").Deserialize()
+	assert.NoError(deserializeErr)
+	assert.Equal("AKIADEV", username)
+	assert.Equal("dev_secret", password)
+	assert.Equal(map[string]map[string]string{
+		global.NO_SECTION_KEY: {"region": "eu-west-1", "source_profile": "default", "aws_session_token": "dev_token"},
+		"s3":                  {"max_concurrent_requests": "20", "addressing_style": "path"},
+	}, attributes)
+
+	// Saving what was loaded leaves the files exactly as they were.
+	assert.NoError(New(testFactory, "dev").Serialize(username, password, attributes))
+	assertFileContents(t, credentialFile, testAwsCredentials)
+	assertFileContents(t, configFile, testAwsConfig)
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
+func TestToAws(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing saving profiles to the AWS shared credentials and config files.")
+	testFactory, credentialFile, configFile := createTestAws(t)
+	assert.NoError(os.MkdirAll(filepath.Dir(credentialFile), 0700))
+	assert.NoError(ioutil.WriteFile(credentialFile, []byte(testAwsCredentials), 0600))
+	assert.NoError(ioutil.WriteFile(configFile, []byte(testAwsConfig), 0600))
+
+	devSerializer := New(testFactory, "dev")
+	assert.NoError(devSerializer.Serialize("AKIADEV", "new_secret", map[string]map[string]string{
+		global.NO_SECTION_KEY: {"region": "eu-west-1", "output": "json", "aws_session_token": "new_token"},
+		"s3":                  {"max_concurrent_requests": "10", "addressing_style": "path"},
+		"sts":                 {"regional_endpoints": "regional"},
+	}))
+	assertFileContents(t, credentialFile, `# managed by hand
+[default]
+aws_access_key_id = AKIADEFAULT
+aws_secret_access_key = default_secret
+
+[dev]
+aws_access_key_id=AKIADEV
+aws_secret_access_key = new_secret
+aws_session_token = new_token
+`)
+	assertFileContents(t, configFile, `[default]
+region = us-east-1
+
+[profile dev]
+# the role used for dev
+region = eu-west-1
+s3 =
+    max_concurrent_requests = 10
+    addressing_style = path
+cli-pager =
+output = json
+sts =
+    regional_endpoints = regional
+
+[sso-session corp]
+sso_region = us-east-1
+`)
+
+	assert.NoError(devSerializer.SerializeProfile(map[string]map[string]string{global.NO_SECTION_KEY: {"region": "eu-west-2"}}))
+	username, password, attributes, deserializeErr := devSerializer.Deserialize()
+	assert.NoError(deserializeErr)
+	assert.Equal("AKIADEV", username)
+	assert.Equal("new_secret", password)
+	assert.Equal(map[string]map[string]string{global.NO_SECTION_KEY: {"region": "eu-west-2"}}, attributes)
+
+	newSerializer := New(testFactory, global.TEST_VAR_FIRST_PROFILE_LABEL)
+	assert.NoError(newSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, map[string]map[string]string{
+		global.NO_SECTION_KEY: {"region": "ap-southeast-2"},
+	}))
+	configContents, readErr := ioutil.ReadFile(configFile)
+	assert.NoError(readErr)
+	assert.Contains(string(configContents), "\n\n[profile "+global.TEST_VAR_FIRST_PROFILE_LABEL+"]\nregion = ap-southeast-2\n")
+
+	assert.NoError(devSerializer.Delete())
+	profiles, listErr := ListProfiles(testFactory)
+	assert.NoError(listErr)
+	assert.Equal([]string{global.DEFAULT_PROFILE_NAME, global.TEST_VAR_FIRST_PROFILE_LABEL}, profiles)
+
+	files, filesErr := newSerializer.GetStorageFiles()
+	assert.NoError(filesErr)
+	assert.Equal([]string{credentialFile, configFile}, files)
+
+	for _, fileName := range files {
+		fileInfo, statErr := os.Stat(fileName)
+		assert.NoError(statErr)
+		assert.Equal(os.FileMode(0600), fileInfo.Mode().Perm())
+		version, versionErr := GetFileFormatVersion(fileName)
+		assert.NoError(versionErr)
+		assert.Equal("", version)
+	}
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
+func assertFileContents(t *testing.T, fileName string, expected string) {
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	if string(contents) != expected {
+		t.Errorf("%s contains:\n%s\nexpected:\n%s", fileName, contents, expected)
+	}
+}
@@ -0,0 +1,317 @@
+package serializer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const awsNestedIndent = "    "
+
+/*
+awsFile is an AWS shared credentials or config file, kept as the lines it was read from so that only the keys which are
+changed are rewritten. The AWS files allow things the ini library does not keep, such as nested settings:
+
+	[profile dev]
+	region = eu-west-1
+	s3 =
+	    max_concurrent_requests = 20
+
+preamble is everything before the first section.
+*/
+type awsFile struct {
+	preamble []*awsEntry
+	sections []*awsSection
+}
+
+// awsSection is a [section] of an awsFile, with the original header line.
+type awsSection struct {
+	name    string
+	header  string
+	entries []*awsEntry
+}
+
+/*
+awsEntry is a line of a section, with any indented lines that follow it. For a key, line is "key = value", and children
+holds the indented sub-keys of a nested setting. For comments and blank lines, key is blank.
+*/
+type awsEntry struct {
+	key      string
+	value    string
+	line     string
+	children []*awsEntry
+}
+
+var awsSectionRegex = regexp.MustCompile(`^\s*\[([^\]]*)\]`)
+
+func loadAwsFile(fileName string) (*awsFile, error) {
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return nil, readErr
+	}
+
+	return parseAwsFile(string(contents)), nil
+}
+
+func parseAwsFile(contents string) *awsFile {
+	myFile := &awsFile{}
+	var current *awsSection
+
+	if contents == "" {
+		return myFile
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(contents, "\n"), "\n") {
+		if match := awsSectionRegex.FindStringSubmatch(line); match != nil {
+			current = &awsSection{name: strings.TrimSpace(match[1]), header: line}
+			myFile.sections = append(myFile.sections, current)
+			continue
+		}
+
+		myEntry := parseAwsEntry(line)
+
+		if current == nil {
+			myFile.preamble = append(myFile.preamble, myEntry)
+			continue
+		}
+
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+
+		if last := current.last(); indented && myEntry.line != "" && last != nil && last.key != "" {
+			last.children = append(last.children, myEntry)
+			continue
+		}
+
+		current.entries = append(current.entries, myEntry)
+	}
+
+	return myFile
+}
+
+func parseAwsEntry(line string) *awsEntry {
+	trimmed := strings.TrimSpace(line)
+	separator := strings.Index(trimmed, "=")
+
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") || separator < 1 {
+		return &awsEntry{line: line}
+	}
+
+	return &awsEntry{
+		key:   strings.TrimSpace(trimmed[:separator]),
+		value: strings.TrimSpace(trimmed[separator+1:]),
+		line:  line,
+	}
+}
+
+/*
+save writes the file with 0600 permissions, creating its directory with 0700 permissions if it does not exist. The AWS
+files hold every profile, not only the ones this library manages, so they are replaced with WriteFileAtomic rather than
+rewritten in place.
+*/
+func (thisFile *awsFile) save(fileName string) error {
+	if mkdirErr := os.MkdirAll(filepath.Dir(fileName), 0700); mkdirErr != nil {
+		return mkdirErr
+	}
+
+	var builder strings.Builder
+
+	for _, myEntry := range thisFile.preamble {
+		myEntry.writeTo(&builder)
+	}
+
+	for _, mySection := range thisFile.sections {
+		builder.WriteString(mySection.header + "\n")
+
+		for _, myEntry := range mySection.entries {
+			myEntry.writeTo(&builder)
+		}
+	}
+
+	return WriteFileAtomic(fileName, []byte(builder.String()), 0600)
+}
+
+func (thisEntry *awsEntry) writeTo(builder *strings.Builder) {
+	builder.WriteString(thisEntry.line + "\n")
+
+	for _, child := range thisEntry.children {
+		builder.WriteString(child.line + "\n")
+	}
+}
+
+func (thisFile *awsFile) section(name string) *awsSection {
+	for _, mySection := range thisFile.sections {
+		if mySection.name == name {
+			return mySection
+		}
+	}
+
+	return nil
+}
+
+// addSection adds an empty section to the end of the file, separated from the section before it by a blank line.
+func (thisFile *awsFile) addSection(name string) *awsSection {
+	if count := len(thisFile.sections); count > 0 {
+		if last := thisFile.sections[count-1]; last.last() != nil && strings.TrimSpace(last.last().line) != "" {
+			last.entries = append(last.entries, &awsEntry{})
+		}
+	}
+
+	mySection := &awsSection{name: name, header: "[" + name + "]"}
+	thisFile.sections = append(thisFile.sections, mySection)
+	return mySection
+}
+
+func (thisFile *awsFile) deleteSection(name string) {
+	for i, mySection := range thisFile.sections {
+		if mySection.name == name {
+			thisFile.sections = append(thisFile.sections[:i], thisFile.sections[i+1:]...)
+			return
+		}
+	}
+}
+
+func (thisSection *awsSection) last() *awsEntry {
+	if len(thisSection.entries) == 0 {
+		return nil
+	}
+
+	return thisSection.entries[len(thisSection.entries)-1]
+}
+
+func (thisSection *awsSection) find(key string) *awsEntry {
+	if thisSection == nil {
+		return nil
+	}
+
+	for _, myEntry := range thisSection.entries {
+		if myEntry.key == key {
+			return myEntry
+		}
+	}
+
+	return nil
+}
+
+// values returns the plain keys of the section, and the sub-keys of each nested setting.
+func (thisSection *awsSection) values() (map[string]string, map[string]map[string]string) {
+	values := make(map[string]string)
+	nested := make(map[string]map[string]string)
+
+	if thisSection == nil {
+		return values, nested
+	}
+
+	for _, myEntry := range thisSection.entries {
+		if myEntry.key == "" {
+			continue
+		}
+
+		if !myEntry.isNested() {
+			values[myEntry.key] = myEntry.value
+			continue
+		}
+
+		nested[myEntry.key] = make(map[string]string)
+
+		for _, child := range myEntry.children {
+			if child.key != "" {
+				nested[myEntry.key][child.key] = child.value
+			}
+		}
+	}
+
+	return values, nested
+}
+
+func (thisEntry *awsEntry) isNested() bool {
+	return thisEntry.value == "" && len(thisEntry.children) > 0
+}
+
+// set sets a plain key, rewriting its line only if the value has changed, and adding it after the last key if it is new.
+func (thisSection *awsSection) set(key string, value string) {
+	if myEntry := thisSection.find(key); myEntry != nil {
+		if myEntry.value != value || myEntry.isNested() {
+			myEntry.value, myEntry.line, myEntry.children = value, key+" = "+value, nil
+		}
+
+		return
+	}
+
+	thisSection.insert(&awsEntry{key: key, value: value, line: key + " = " + value})
+}
+
+// setNested makes the sub-keys of a nested setting match values, keeping the lines of those that have not changed.
+func (thisSection *awsSection) setNested(key string, values map[string]string) {
+	myEntry := thisSection.find(key)
+
+	if myEntry == nil {
+		myEntry = &awsEntry{key: key, line: key + " ="}
+		thisSection.insert(myEntry)
+	} else if !myEntry.isNested() {
+		myEntry.value, myEntry.line = "", key+" ="
+	}
+
+	indent := awsNestedIndent
+	var children []*awsEntry
+
+	for _, child := range myEntry.children {
+		if child.key == "" {
+			children = append(children, child)
+			continue
+		}
+
+		indent = child.line[:len(child.line)-len(strings.TrimLeft(child.line, " \t"))]
+		value, exists := values[child.key]
+
+		if !exists {
+			continue
+		}
+
+		if child.value != value {
+			child.value, child.line = value, indent+child.key+" = "+value
+		}
+
+		children = append(children, child)
+	}
+
+	for _, childKey := range sortedKeys(values) {
+		if nestedEntryIndex(children, childKey) < 0 {
+			children = append(children, &awsEntry{key: childKey, value: values[childKey], line: indent + childKey + " = " + values[childKey]})
+		}
+	}
+
+	myEntry.children = children
+}
+
+func nestedEntryIndex(entries []*awsEntry, key string) int {
+	for i, myEntry := range entries {
+		if myEntry.key == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// insert adds an entry after the last line of the section that is not blank.
+func (thisSection *awsSection) insert(myEntry *awsEntry) {
+	insertAt := len(thisSection.entries)
+
+	for insertAt > 0 && strings.TrimSpace(thisSection.entries[insertAt-1].line) == "" && len(thisSection.entries[insertAt-1].children) == 0 {
+		insertAt--
+	}
+
+	thisSection.entries = append(thisSection.entries, nil)
+	copy(thisSection.entries[insertAt+1:], thisSection.entries[insertAt:])
+	thisSection.entries[insertAt] = myEntry
+}
+
+func (thisSection *awsSection) delete(key string) {
+	if index := nestedEntryIndex(thisSection.entries, key); index >= 0 {
+		thisSection.entries = append(thisSection.entries[:index], thisSection.entries[index+1:]...)
+	}
+}
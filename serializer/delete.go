@@ -0,0 +1,88 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+/*
+Delete removes the profile from storage. For the file output types, its entry is removed from the credentials file
+(whichever format that file was written in) and its config file is deleted; the other profiles are left alone. For the
+env output type, the profile's variables are removed as with ClearEnv. For the aws output type, the profile's sections
+are removed from the AWS credentials and config files. Deleting a profile that is not stored is not an
+error.
+*/
+func (thisSerializer *Serializer) Delete() error {
+	outputType := thisSerializer.Factory.OutputType
+	thisSerializer.Factory.Log.Info().Str("profile", thisSerializer.ProfileName).Str("output_type", outputType).Msg("Deleting profile.")
+
+	if outputType == global.OUTPUT_TYPE_ENV {
+		return thisSerializer.ClearEnv()
+	}
+
+	if outputType == global.OUTPUT_TYPE_AWS {
+		return thisSerializer.deleteAws()
+	}
+
+	if outputType != global.OUTPUT_TYPE_INI && outputType != global.OUTPUT_TYPE_JSON {
+		thisSerializer.Factory.Log.Error().Str("unrecognized", outputType).Msg(ERR_UNRECOGNIZED_OUTPUT_TYPE)
+		return errors.New(ERR_UNRECOGNIZED_OUTPUT_TYPE)
+	}
+
+	credentialType, detectErr := DetectFileType(thisSerializer.CredentialFile)
+
+	if detectErr != nil {
+		return detectErr
+	}
+
+	var deleteErr error
+
+	if credentialType == global.OUTPUT_TYPE_INI {
+		deleteErr = thisSerializer.deleteCredentialIni()
+	} else if credentialType == global.OUTPUT_TYPE_JSON {
+		deleteErr = thisSerializer.deleteCredentialJson()
+	}
+
+	if deleteErr != nil {
+		return deleteErr
+	}
+
+	removeErr := os.Remove(thisSerializer.ConfigFile)
+
+	if removeErr != nil && !os.IsNotExist(removeErr) {
+		return removeErr
+	}
+
+	return nil
+}
+
+func (thisSerializer *Serializer) deleteCredentialIni() error {
+	credentialIni, initErr := initIni(thisSerializer.CredentialFile)
+
+	if initErr != nil {
+		return initErr
+	}
+
+	credentialIni.DeleteSection(thisSerializer.ProfileName)
+	return saveIniAtomic(credentialIni, thisSerializer.CredentialFile)
+}
+
+func (thisSerializer *Serializer) deleteCredentialJson() error {
+	existingCredential, initErr := initJsonCredential(thisSerializer.CredentialFile)
+
+	if initErr != nil {
+		return initErr
+	}
+
+	delete(existingCredential.Credentials, thisSerializer.ProfileName)
+	outJson, marshalErr := json.MarshalIndent(existingCredential, "", global.INDENT_JSON)
+
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	return WriteFileAtomic(thisSerializer.CredentialFile, outJson, 0600)
+}
@@ -0,0 +1,51 @@
+package serializer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestDelete(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing deleting a profile from storage.")
+
+	for _, fileType := range GetSupportedFileTypes() {
+		log.Info().Msgf("Testing the '%v' file type.", fileType)
+		testFactory, testSerializer, serializeErr := createTestIni(global.DEFAULT_PROFILE_NAME, false)
+
+		if fileType == global.OUTPUT_TYPE_JSON {
+			os.RemoveAll(testFactory.ParentDirectory)
+			testFactory, testSerializer, serializeErr = createTestJson(global.DEFAULT_PROFILE_NAME, false)
+		}
+
+		assert.NoError(serializeErr)
+		_, _, serializeErr = createTestIni(global.TEST_VAR_FIRST_PROFILE_LABEL, true)
+
+		if fileType == global.OUTPUT_TYPE_JSON {
+			_, _, serializeErr = createTestJson(global.TEST_VAR_FIRST_PROFILE_LABEL, true)
+		}
+
+		assert.NoError(serializeErr)
+
+		deleteErr := testSerializer.Delete()
+		assert.NoError(deleteErr)
+		assert.NoFileExists(testSerializer.ConfigFile)
+
+		profiles, listErr := ListProfiles(testFactory)
+		assert.NoError(listErr)
+		assert.Equal([]string{global.TEST_VAR_FIRST_PROFILE_LABEL}, profiles)
+
+		deleteErr = testSerializer.Delete()
+		assert.NoError(deleteErr)
+		os.RemoveAll(testFactory.ParentDirectory)
+	}
+
+	testFactory, testSerializer, serializeErr := createTestEnv(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+	deleteErr := testSerializer.Delete()
+	assert.NoError(deleteErr)
+	_, exists := testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL]
+	assert.False(exists)
+}
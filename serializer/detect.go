@@ -0,0 +1,169 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+/*
+DetectFileType inspects the contents of fileName to work out which of the supported file types it was written as. A
+file whose first non-whitespace character is { is json, anything else is ini. If the file does not exist or is empty,
+a blank string is returned as there is nothing to detect.
+*/
+func DetectFileType(fileName string) (string, error) {
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return "", nil
+		}
+
+		return "", readErr
+	}
+
+	contents = bytes.TrimPrefix(contents, []byte("\xef\xbb\xbf"))
+	contents = bytes.TrimSpace(contents)
+
+	if len(contents) == 0 {
+		return "", nil
+	}
+
+	if contents[0] == '{' {
+		return global.OUTPUT_TYPE_JSON, nil
+	}
+
+	return global.OUTPUT_TYPE_INI, nil
+}
+
+/*
+NewDetectedFactory creates a Factory for applicationName with outputType set. If outputType is blank, the type the
+existing credentials file was written in is used (see DetectFileType), or the Factory's default if there is no file.
+*/
+func NewDetectedFactory(applicationName string, outputType string) (*factory.Factory, error) {
+	sourceFactory, factoryErr := factory.New(applicationName)
+
+	if factoryErr != nil {
+		return nil, factoryErr
+	}
+
+	if outputType == "" {
+		detectedType, detectErr := DetectFileType(sourceFactory.CredentialFile)
+
+		if detectErr != nil {
+			return nil, detectErr
+		}
+
+		outputType = detectedType
+	}
+
+	if outputType != "" {
+		if outputErr := sourceFactory.SetOutputType(outputType); outputErr != nil {
+			return nil, outputErr
+		}
+	}
+
+	return sourceFactory, nil
+}
+
+// detectFileTypeOrDefault returns the detected type of fileName, or outputType if the file has nothing to detect.
+func (thisSerializer *Serializer) detectFileTypeOrDefault(fileName string, outputType string) (string, error) {
+	detectedType, detectErr := DetectFileType(fileName)
+
+	if detectErr != nil {
+		return "", detectErr
+	}
+
+	if detectedType == "" {
+		return outputType, nil
+	}
+
+	if detectedType != outputType {
+		thisSerializer.Factory.Log.Warn().Str("file", fileName).Str("output_type", outputType).Str("detected", detectedType).Msg("File is not in the configured output type.")
+	}
+
+	return detectedType, nil
+}
+
+/*
+deserializeFile loads the credential and profile from the files, detecting the format of each file rather than
+trusting outputType. The credentials file and the config file are detected separately, so a profile can be loaded
+even if only one of them has been migrated.
+*/
+func (thisSerializer *Serializer) deserializeFile(outputType string) (string, string, map[string]map[string]string, error) {
+	credentialType, detectErr := thisSerializer.detectFileTypeOrDefault(thisSerializer.CredentialFile, outputType)
+
+	if detectErr != nil {
+		return "", "", make(map[string]map[string]string), detectErr
+	}
+
+	configType, detectErr := thisSerializer.detectFileTypeOrDefault(thisSerializer.ConfigFile, outputType)
+
+	if detectErr != nil {
+		return "", "", make(map[string]map[string]string), detectErr
+	}
+
+	var username, password string
+	var credentialErr error
+
+	if credentialType == global.OUTPUT_TYPE_JSON {
+		username, password, credentialErr = thisSerializer.loadCredentialJson()
+	} else {
+		username, password, credentialErr = thisSerializer.loadCredentialIni()
+	}
+
+	if credentialErr != nil {
+		return "", "", make(map[string]map[string]string), credentialErr
+	}
+
+	var attributes map[string]map[string]string
+	var attributeErr error
+
+	if configType == global.OUTPUT_TYPE_JSON {
+		attributes, attributeErr = thisSerializer.loadProfileJson()
+	} else {
+		attributes, attributeErr = thisSerializer.loadProfileIni()
+	}
+
+	if attributeErr != nil {
+		return "", "", make(map[string]map[string]string), attributeErr
+	}
+
+	return username, password, attributes, nil
+}
+
+/*
+checkFileTypes makes sure that saving in outputType will not corrupt the existing files. The credentials file is shared
+by every profile, so if it was written in another format an error is returned (see Migrate). The config file only
+belongs to this profile and is about to be rewritten in full, so if it is in another format it is replaced, which is
+done by writing the new file alongside it and renaming it over the old one, so the attributes are never lost.
+*/
+func (thisSerializer *Serializer) checkFileTypes(outputType string) error {
+	credentialType, detectErr := DetectFileType(thisSerializer.CredentialFile)
+
+	if detectErr != nil {
+		return detectErr
+	}
+
+	if credentialType != "" && credentialType != outputType {
+		thisSerializer.Factory.Log.Error().Str("file", thisSerializer.CredentialFile).Str("detected", credentialType).Msg(ERR_FILE_TYPE_MISMATCH)
+		return errors.New(ERR_FILE_TYPE_MISMATCH)
+	}
+
+	configType, detectErr := DetectFileType(thisSerializer.ConfigFile)
+
+	if detectErr != nil {
+		return detectErr
+	}
+
+	if configType != "" && configType != outputType {
+		thisSerializer.Factory.Log.Warn().Str("file", thisSerializer.ConfigFile).Str("detected", configType).Msg("Replacing config file written in another format.")
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package serializer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestDetectFileType(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing detecting the format of a file.")
+	testSerializer := createTestExport()
+	testFile := testSerializer.Factory.ParentDirectory + "detect"
+
+	detectedType, detectErr := DetectFileType(testFile)
+	assert.NoError(detectErr)
+	assert.Equal("", detectedType)
+
+	expectedTypes := map[string]string{
+		"":                           "",
+		" \n\t":                      "",
+		"{}":                         global.OUTPUT_TYPE_JSON,
+		"\xef\xbb\xbf\n  {\"a\": 1}": global.OUTPUT_TYPE_JSON,
+		"[default]\nusername = a":    global.OUTPUT_TYPE_INI,
+		"; a comment\nkey = value":   global.OUTPUT_TYPE_INI,
+	}
+
+	for contents, expectedType := range expectedTypes {
+		writeErr := ioutil.WriteFile(testFile, []byte(contents), 0600)
+		assert.NoError(writeErr)
+		detectedType, detectErr = DetectFileType(testFile)
+		assert.NoError(detectErr)
+		assert.Equal(expectedType, detectedType)
+	}
+
+	os.RemoveAll(testSerializer.Factory.ParentDirectory)
+}
+
+func TestDeserializeDetectsFileType(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing loading json files with a factory set to ini and vice versa.")
+	testFactory, _, serializeErr := createTestJson(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+
+	testFactory.SetOutputType(global.OUTPUT_TYPE_INI)
+	username, password, attributes, deserializeErr := New(testFactory, global.DEFAULT_PROFILE_NAME).Deserialize()
+	assert.NoError(deserializeErr)
+	assert.Equal(global.TEST_VAR_USERNAME, username)
+	assert.Equal(global.TEST_VAR_PASSWORD, password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+
+	os.RemoveAll(testFactory.ParentDirectory)
+
+	testFactory, _, serializeErr = createTestIni(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+
+	testFactory.SetOutputType(global.OUTPUT_TYPE_JSON)
+	username, password, attributes, deserializeErr = New(testFactory, global.DEFAULT_PROFILE_NAME).Deserialize()
+	assert.NoError(deserializeErr)
+	assert.Equal(global.TEST_VAR_USERNAME, username)
+	assert.Equal(global.TEST_VAR_PASSWORD, password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
+func TestSerializeFileTypeMismatch(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing saving over files written in another format.")
+	testFactory, testSerializer, serializeErr := createTestJson(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+
+	testFactory.SetOutputType(global.OUTPUT_TYPE_INI)
+	serializeErr = testSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil)
+	assert.EqualError(serializeErr, ERR_FILE_TYPE_MISMATCH)
+
+	detectedType, detectErr := DetectFileType(testFactory.CredentialFile)
+	assert.NoError(detectErr)
+	assert.Equal(global.OUTPUT_TYPE_JSON, detectedType)
+
+	removeErr := os.Remove(testFactory.CredentialFile)
+	assert.NoError(removeErr)
+	attributes := map[string]map[string]string{
+		global.TEST_VAR_FIRST_SECTION_KEY: {global.TEST_VAR_ATTRIBUTE_NAME_LABEL: global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED},
+	}
+	serializeErr = testSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, attributes)
+	assert.NoError(serializeErr)
+
+	// The config file is replaced rather than removed and written again, which leaves nothing else behind.
+	detectedType, detectErr = DetectFileType(testSerializer.ConfigFile)
+	assert.NoError(detectErr)
+	assert.NotEqual(global.OUTPUT_TYPE_JSON, detectedType)
+	_, _, loadedAttributes, loadErr := testSerializer.Deserialize()
+	assert.NoError(loadErr)
+	assert.Equal(attributes[global.TEST_VAR_FIRST_SECTION_KEY], loadedAttributes[global.TEST_VAR_FIRST_SECTION_KEY])
+	configFiles, readErr := ioutil.ReadDir(filepath.Dir(testSerializer.ConfigFile))
+	assert.NoError(readErr)
+	assert.Len(configFiles, 1)
+
+	// And back again to json.
+	testFactory.SetOutputType(global.OUTPUT_TYPE_JSON)
+	os.Remove(testFactory.CredentialFile)
+	assert.NoError(testSerializer.SerializeProfile(attributes))
+	detectedType, detectErr = DetectFileType(testSerializer.ConfigFile)
+	assert.NoError(detectErr)
+	assert.Equal(global.OUTPUT_TYPE_JSON, detectedType)
+	loadedAttributes, loadErr = testSerializer.loadProfileJson()
+	assert.NoError(loadErr)
+	assert.Equal(attributes[global.TEST_VAR_FIRST_SECTION_KEY], loadedAttributes[global.TEST_VAR_FIRST_SECTION_KEY])
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
@@ -0,0 +1,154 @@
+package serializer
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+)
+
+/*
+FromDotEnv is responsible for deserializing a Credential and Profile from a dotenv file, such as one written by Export
+with global.EXPORT_TYPE_DOTENV. The variables in the file must use the same key layout as ToEnv:
+
+	APPLICATION_NAME::PROFILE_NAME::USERNAME (or alternate)
+	APPLICATION_NAME::PROFILE_NAME::PASSWORD (or alternate)
+	APPLICATION_NAME::PROFILE_NAME::ATTRIBUTE::SECTION_NAME::KEY_VALUE
+
+The file is parsed without touching the environment of the current process. Blank lines, lines starting with # and
+an optional leading "export " are ignored. Values may be unquoted (an unquoted " #" starts a comment), single quoted
+(taken literally) or double quoted (\n, \r, \t, \\, \", \$ and \` are unescaped). Quoted values may span lines.
+*/
+func (thisSerializer *Serializer) FromDotEnv(fileName string) (string, string, map[string]map[string]string, error) {
+	thisSerializer.Factory.Log.Info().Str("file", fileName).Msg("Deserializing credential and profile from dotenv file.")
+
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		thisSerializer.Factory.Log.Error().Str("file", fileName).Err(readErr).Msg("Error reading dotenv file.")
+		return "", "", make(map[string]map[string]string), readErr
+	}
+
+	envVariables, parseErr := parseDotEnv(string(contents))
+
+	if parseErr != nil {
+		thisSerializer.Factory.Log.Error().Str("file", fileName).Err(parseErr).Msg("Error parsing dotenv file.")
+		return "", "", make(map[string]map[string]string), parseErr
+	}
+
+	return thisSerializer.fromVariablesEnv(envVariables)
+}
+
+// parseDotEnv turns the contents of a dotenv file into a map of variables. Later definitions override earlier ones.
+func parseDotEnv(contents string) (map[string]string, error) {
+	envVariables := make(map[string]string)
+	contents = strings.Replace(contents, "\r\n", "\n", -1)
+
+	for len(contents) > 0 {
+		var line string
+		line, contents = nextDotEnvLine(contents)
+		line = strings.TrimLeft(line, " \t")
+
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "export ") {
+			line = strings.TrimLeft(line[len("export "):], " \t")
+		}
+
+		splitIndex := strings.Index(line, "=")
+
+		if splitIndex < 1 {
+			return nil, errors.New(ERR_DOTENV_INVALID_LINE)
+		}
+
+		key := strings.TrimSpace(line[:splitIndex])
+		rawValue := strings.TrimLeft(line[splitIndex+1:], " \t")
+
+		if strings.ContainsAny(key, " \t") {
+			return nil, errors.New(ERR_DOTENV_INVALID_LINE)
+		}
+
+		var value string
+		var valueErr error
+
+		if strings.HasPrefix(rawValue, "\"") || strings.HasPrefix(rawValue, "'") {
+			// quoted values may continue over the following lines, so hand the rest of the file to the parser.
+			value, contents, valueErr = parseDotEnvQuoted(rawValue + "\n" + contents)
+
+			if valueErr != nil {
+				return nil, valueErr
+			}
+		} else {
+			value = rawValue
+
+			if commentIndex := strings.Index(value, " #"); commentIndex >= 0 {
+				value = value[:commentIndex]
+			}
+
+			if commentIndex := strings.Index(value, "\t#"); commentIndex >= 0 {
+				value = value[:commentIndex]
+			}
+
+			value = strings.TrimSpace(value)
+		}
+
+		envVariables[key] = value
+	}
+
+	return envVariables, nil
+}
+
+func nextDotEnvLine(contents string) (string, string) {
+	lineEnd := strings.Index(contents, "\n")
+
+	if lineEnd < 0 {
+		return contents, ""
+	}
+
+	return contents[:lineEnd], contents[lineEnd+1:]
+}
+
+// parseDotEnvQuoted reads a quoted value from the start of contents, returning the value and whatever follows the line
+// the closing quote is on. Anything after the closing quote other than whitespace or a comment is an error.
+func parseDotEnvQuoted(contents string) (string, string, error) {
+	quote := contents[0]
+	var builder strings.Builder
+	i := 1
+
+	for ; i < len(contents) && contents[i] != quote; i++ {
+		if quote == '"' && contents[i] == '\\' && i+1 < len(contents) {
+			i++
+
+			switch contents[i] {
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case '\\', '"', '$', '`':
+				builder.WriteByte(contents[i])
+			default:
+				builder.WriteByte('\\')
+				builder.WriteByte(contents[i])
+			}
+		} else {
+			builder.WriteByte(contents[i])
+		}
+	}
+
+	if i >= len(contents) {
+		return "", "", errors.New(ERR_DOTENV_UNTERMINATED_QUOTE)
+	}
+
+	remainder, rest := nextDotEnvLine(contents[i+1:])
+	remainder = strings.TrimSpace(remainder)
+
+	if remainder != "" && !strings.HasPrefix(remainder, "#") {
+		return "", "", errors.New(ERR_DOTENV_INVALID_LINE)
+	}
+
+	return builder.String(), rest, nil
+}
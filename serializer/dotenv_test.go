@@ -0,0 +1,87 @@
+package serializer
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestFromDotEnv(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing loading a profile from an exported dotenv file.")
+	testSerializer := createTestExport()
+	dotEnvFile := testSerializer.Factory.ParentDirectory + ".env"
+
+	for _, password := range []string{global.TEST_VAR_PASSWORD, global.TEST_VAR_PASSWORD_ALTERNATE, "it's \"quoted\"\nover two lines # not a comment"} {
+		exportErr := testSerializer.ExportToFile(global.EXPORT_TYPE_DOTENV, dotEnvFile, global.TEST_VAR_USERNAME, password, createTestExportAttributes())
+		assert.NoError(exportErr)
+
+		username, loadedPassword, attributes, loadErr := testSerializer.FromDotEnv(dotEnvFile)
+		assert.NoError(loadErr)
+		assert.Equal(global.TEST_VAR_USERNAME, username)
+		assert.Equal(password, loadedPassword)
+		assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+	}
+
+	_, exists := os.LookupEnv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
+	assert.False(exists)
+
+	os.RemoveAll(testSerializer.Factory.ParentDirectory)
+}
+
+func TestFromDotEnvHandWritten(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing loading a profile from a hand written dotenv file.")
+	testSerializer := createTestExport()
+	dotEnvFile := testSerializer.Factory.ParentDirectory + ".env"
+	contents := "# credentials for local development\n" +
+		"\n" +
+		"export " + global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL + " = " + global.TEST_VAR_USERNAME + " # trailing comment\n" +
+		global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL + "='" + global.TEST_VAR_PASSWORD + "'\n" +
+		"   " + global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL + "=\"a global\\tattribute\" # quoted comment\r\n" +
+		"UNRELATED_VARIABLE=ignored\n"
+	writeErr := ioutil.WriteFile(dotEnvFile, []byte(contents), 0600)
+	assert.NoError(writeErr)
+
+	username, password, attributes, loadErr := testSerializer.FromDotEnv(dotEnvFile)
+	assert.NoError(loadErr)
+	assert.Equal(global.TEST_VAR_USERNAME, username)
+	assert.Equal(global.TEST_VAR_PASSWORD, password)
+	assert.Equal("a global\tattribute", attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+
+	os.RemoveAll(testSerializer.Factory.ParentDirectory)
+}
+
+func TestFromDotEnvErrors(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing loading a profile from bad dotenv files.")
+	testSerializer := createTestExport()
+	dotEnvFile := testSerializer.Factory.ParentDirectory + ".env"
+
+	_, _, _, loadErr := testSerializer.FromDotEnv(dotEnvFile)
+	assert.Error(loadErr)
+
+	badFiles := map[string]string{
+		"NO_EQUALS_SIGN\n":       ERR_DOTENV_INVALID_LINE,
+		"=no_key\n":              ERR_DOTENV_INVALID_LINE,
+		"KEY=\"unterminated\n":   ERR_DOTENV_UNTERMINATED_QUOTE,
+		"KEY='value' trailing\n": ERR_DOTENV_INVALID_LINE,
+		global.TEST_VAR_ENVIRONMENT_BAD_LABEL + "=1": ERR_DOTENV_INVALID_LINE,
+	}
+
+	for contents, expectedErr := range badFiles {
+		writeErr := ioutil.WriteFile(dotEnvFile, []byte(contents), 0600)
+		assert.NoError(writeErr)
+		_, _, _, loadErr = testSerializer.FromDotEnv(dotEnvFile)
+		assert.EqualError(loadErr, expectedErr)
+	}
+
+	writeErr := ioutil.WriteFile(dotEnvFile, []byte(global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL+"=only_a_password\n"), 0600)
+	assert.NoError(writeErr)
+	_, _, _, loadErr = testSerializer.FromDotEnv(dotEnvFile)
+	assert.EqualError(loadErr, ERR_REQUIRED_VARIABLE_USERNAME_NOT_FOUND)
+
+	os.RemoveAll(testSerializer.Factory.ParentDirectory)
+}
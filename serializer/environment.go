@@ -3,13 +3,12 @@ package serializer
 import (
 	"errors"
 	"github.com/engi-fyi/go-credentials/global"
-	"os"
 	"strings"
 )
 
 /*
-ToEnv is responsible for serializing a Credential/Profile combination into the environment. Both of the credential
-values are serialized as so:
+ToEnv is responsible for serializing a Credential/Profile combination into the Factory's environment (see
+factory.SetEnvironment). Both of the credential values are serialized as so:
 
 	APPLICATION_NAME::PROFILE_NAME::USERNAME (or alternate)
 	APPLICATION_NAME::PROFILE_NAME::PASSWORD (or alternate)
@@ -18,75 +17,127 @@ Profile attributes are also added to the profile in the following format:
 
 	APPLICATION_NAME::PROFILE_NAME::ATTRIBUTE::SECTION_NAME::KEY_VALUE
 
-SECTION_NAME can be blank.
+SECTION_NAME can be blank. Any variables left over from a previous save of the same profile (such as deleted
+attributes) are removed from the Factory's environment first. The environment of the current process is not modified
+unless UseEnvironment is set on the Factory, in which case the left over variables are removed from it too and
+factory.ApplyEnvironment is called once the variables have been set.
 */
 func (thisSerializer *Serializer) ToEnv(username string, password string, attributes map[string]map[string]string) error {
 	thisSerializer.Factory.Log.Info().Msg("Serializing credential and profile to environment.")
-	credentialErr := thisSerializer.saveCredentialEnv(username, password)
+	environment := thisSerializer.Factory.GetEnvironment()
 
-	if credentialErr != nil {
-		return credentialErr
+	if clearErr := thisSerializer.clearEnv(environment, thisSerializer.getEnvPrefix()); clearErr != nil {
+		return clearErr
 	}
 
-	profileErr := thisSerializer.saveProfileEnv(attributes)
+	thisSerializer.saveCredentialEnv(environment, username, password)
+	thisSerializer.saveProfileEnv(environment, attributes)
 
-	if profileErr != nil {
-		return profileErr
+	if thisSerializer.Factory.UseEnvironment {
+		thisSerializer.Factory.Log.Debug().Msg("Applying environment to the current process.")
+		return thisSerializer.Factory.ApplyEnvironment()
 	}
 
 	return nil
 }
 
-func (thisSerializer *Serializer) saveCredentialEnv(username string, password string) error {
-	prefix := thisSerializer.getEnvPrefix()
+// toProfileEnv replaces the attribute variables of the profile, keeping its username and password variables.
+func (thisSerializer *Serializer) toProfileEnv(attributes map[string]map[string]string) error {
+	thisSerializer.Factory.Log.Info().Msg("Serializing profile to environment.")
+	environment := thisSerializer.Factory.GetEnvironment()
 
-	usernameKey := strings.ToUpper(prefix + thisSerializer.Factory.GetAlternateUsername())
-	thisSerializer.Factory.Log.Trace().Str("key", usernameKey).Msg("Setting username environment variable.")
-	setErr := os.Setenv(usernameKey, username)
+	if clearErr := thisSerializer.clearEnv(environment, thisSerializer.getEnvPrefix()+"ATTRIBUTE::"); clearErr != nil {
+		return clearErr
+	}
+
+	thisSerializer.saveProfileEnv(environment, attributes)
 
-	if setErr != nil {
-		thisSerializer.Factory.Log.Error().Err(setErr).Msg("Error setting username in environment.")
-		return setErr
+	if thisSerializer.Factory.UseEnvironment {
+		thisSerializer.Factory.Log.Debug().Msg("Applying environment to the current process.")
+		return thisSerializer.Factory.ApplyEnvironment()
 	}
 
-	thisSerializer.Factory.Log.Info().Msg("Username set.")
+	return nil
+}
 
-	passwordKey := strings.ToUpper(prefix + thisSerializer.Factory.GetAlternatePassword())
-	thisSerializer.Factory.Log.Trace().Str("key", passwordKey).Msg("Setting password environment variable.")
-	setErr = os.Setenv(passwordKey, password)
+/*
+clearEnv removes the variables starting with prefix from environment before a save replaces them. If UseEnvironment is
+set on the Factory they are removed from the environment of the current process as well, as ApplyEnvironment only sets
+variables and would otherwise leave the removed ones behind.
+*/
+func (thisSerializer *Serializer) clearEnv(environment map[string]string, prefix string) error {
+	if thisSerializer.Factory.UseEnvironment {
+		return thisSerializer.Factory.UnsetEnvironment(prefix)
+	}
 
-	if setErr != nil {
-		thisSerializer.Factory.Log.Error().Err(setErr).Msg("Error setting password in environment.")
-		return setErr
+	for key := range environment {
+		if strings.HasPrefix(strings.ToUpper(key), prefix) {
+			delete(environment, key)
+		}
 	}
 
+	return nil
+}
+
+func (thisSerializer *Serializer) saveCredentialEnv(environment map[string]string, username string, password string) {
+	usernameKey := thisSerializer.getEnvUsernameKey()
+	thisSerializer.Factory.Log.Trace().Str("key", usernameKey).Msg("Setting username environment variable.")
+	environment[usernameKey] = username
+	thisSerializer.Factory.Log.Info().Msg("Username set.")
+
+	passwordKey := thisSerializer.getEnvPasswordKey()
+	thisSerializer.Factory.Log.Trace().Str("key", passwordKey).Msg("Setting password environment variable.")
+	environment[passwordKey] = password
 	thisSerializer.Factory.Log.Info().Msg("Password set.")
+}
 
-	return nil
+func (thisSerializer *Serializer) saveProfileEnv(environment map[string]string, attributes map[string]map[string]string) {
+	for _, key := range sortedSectionNames(attributes) {
+		for _, subKey := range sortedKeys(attributes[key]) {
+			fullKey := thisSerializer.getEnvAttributeKey(key, subKey)
+			thisSerializer.Factory.Log.Trace().Str("key", fullKey).Msg("Setting attribute environment variable.")
+			environment[fullKey] = attributes[key][subKey]
+		}
+	}
+
+	thisSerializer.Factory.Log.Trace().Msg("Attribute environment variables set successfully.")
 }
 
-func (thisSerializer *Serializer) saveProfileEnv(attributes map[string]map[string]string) error {
-	prefix := thisSerializer.getEnvPrefix()
+/*
+GetEnvVariables returns the environment variables that ToEnv would set for a Credential/Profile combination, without
+touching the environment of the current process. The keys follow the same format as ToEnv:
+
+	APPLICATION_NAME::PROFILE_NAME::USERNAME (or alternate)
+	APPLICATION_NAME::PROFILE_NAME::PASSWORD (or alternate)
+	APPLICATION_NAME::PROFILE_NAME::ATTRIBUTE::SECTION_NAME::KEY_VALUE
+*/
+func (thisSerializer *Serializer) GetEnvVariables(username string, password string, attributes map[string]map[string]string) map[string]string {
+	variables := make(map[string]string)
+	variables[thisSerializer.getEnvUsernameKey()] = username
+	variables[thisSerializer.getEnvPasswordKey()] = password
 
 	for key, value := range attributes {
 		for subKey, subValue := range value {
-			fullKey := prefix + "ATTRIBUTE::" + strings.ToUpper(key) + "::" + strings.ToUpper(subKey)
-			thisSerializer.Factory.Log.Trace().Str("key", fullKey).Msg("Setting attribute environment variable.")
-			setErr := os.Setenv(fullKey, subValue)
-
-			if setErr != nil {
-				return setErr
-			}
+			variables[thisSerializer.getEnvAttributeKey(key, subKey)] = subValue
 		}
 	}
 
-	thisSerializer.Factory.Log.Trace().Msg("Attribute environment variables set successfully.")
+	return variables
+}
 
-	return nil
+/*
+ClearEnv is the inverse of ToEnv. It removes every variable belonging to the Serializer's profile, i.e. every variable
+starting with APPLICATION_NAME::PROFILE_NAME::, from both the Factory's environment and the environment of the current
+process. Variables belonging to other profiles are left in place; use factory.ClearEnvironment to remove all of them.
+*/
+func (thisSerializer *Serializer) ClearEnv() error {
+	thisSerializer.Factory.Log.Info().Str("profile", thisSerializer.ProfileName).Msg("Clearing profile from environment.")
+	return thisSerializer.Factory.UnsetEnvironment(thisSerializer.getEnvPrefix())
 }
 
 /*
-FromEnv is responsible for scanning environment variables and retrieves applicable variables that have
+FromEnv is responsible for scanning the Factory's environment (see factory.SetEnvironment, by default a copy of the
+environment of the current process) and retrieves applicable variables that have
 the prefix of applicationName and that have at least two "::" in them (which is the separator). The format for an
 environment variable managed by serializer is:
 
@@ -97,7 +148,11 @@ of three values which are USERNAME, PASSWORD, or ATTRIBUTE. If the FIELD_TYPE is
 */
 func (thisSerializer *Serializer) FromEnv() (string, string, map[string]map[string]string, error) {
 	thisSerializer.Factory.Log.Info().Msg("Deserializing credential and profile from environment.")
-	parsedVariables, parseErr := thisSerializer.loadVariablesEnv()
+	return thisSerializer.fromVariablesEnv(thisSerializer.Factory.GetEnvironment())
+}
+
+func (thisSerializer *Serializer) fromVariablesEnv(envVariables map[string]string) (string, string, map[string]map[string]string, error) {
+	parsedVariables, parseErr := thisSerializer.loadVariablesEnv(envVariables)
 
 	if parseErr != nil {
 		return "", "", make(map[string]map[string]string), parseErr
@@ -145,15 +200,12 @@ func (thisSerializer *Serializer) loadProfileEnv(parsedVariables map[string]map[
 	return parsedVariables, nil
 }
 
-func (thisSerializer *Serializer) loadVariablesEnv() (map[string]map[string]string, error) {
-	envVariables := os.Environ()
+func (thisSerializer *Serializer) loadVariablesEnv(envVariables map[string]string) (map[string]map[string]string, error) {
 	parsedVariables := make(map[string]map[string]string)
 	parsedVariables[global.NO_SECTION_KEY] = make(map[string]string)
 
-	for i := range envVariables {
-		splitIndex := strings.Index(envVariables[i], "=")
-		key := strings.ToUpper(envVariables[i][0:splitIndex])
-		value := envVariables[i][splitIndex+1 : len(envVariables[i])]
+	for rawKey, value := range envVariables {
+		key := strings.ToUpper(rawKey)
 		profileName, fieldName, sectionName, didParse := thisSerializer.ParseEnvironmentVariable(key)
 
 		if didParse {
@@ -178,6 +230,18 @@ func (thisSerializer *Serializer) getEnvPrefix() string {
 	return strings.ToUpper(thisSerializer.Factory.ApplicationName) + "::" + strings.ToUpper(thisSerializer.ProfileName) + "::"
 }
 
+func (thisSerializer *Serializer) getEnvUsernameKey() string {
+	return strings.ToUpper(thisSerializer.getEnvPrefix() + thisSerializer.Factory.GetAlternateUsername())
+}
+
+func (thisSerializer *Serializer) getEnvPasswordKey() string {
+	return strings.ToUpper(thisSerializer.getEnvPrefix() + thisSerializer.Factory.GetAlternatePassword())
+}
+
+func (thisSerializer *Serializer) getEnvAttributeKey(sectionName string, key string) string {
+	return thisSerializer.getEnvPrefix() + "ATTRIBUTE::" + strings.ToUpper(sectionName) + "::" + strings.ToUpper(key)
+}
+
 /*
 ParseEnvironmentVariable is able to process an environment variable and see if matches the expected format for our
 environment variables.
@@ -10,24 +10,75 @@ import (
 func TestToEnv(t *testing.T) {
 	assert, _ := global.InitTest(t)
 
-	_, _, serializeErr := createTestEnv(global.DEFAULT_PROFILE_NAME, false)
+	testFactory, _, serializeErr := createTestEnv(global.DEFAULT_PROFILE_NAME, false)
 	assert.NoError(serializeErr)
+	environment := testFactory.GetEnvironment()
 
-	value, exists := os.LookupEnv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
+	value, exists := environment[global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL]
 	assert.True(exists)
 	assert.Equal(global.TEST_VAR_USERNAME, value)
 
-	value, exists = os.LookupEnv(global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL)
+	value, exists = environment[global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL]
 	assert.True(exists)
 	assert.Equal(global.TEST_VAR_PASSWORD, value)
 
-	value, exists = os.LookupEnv(global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL)
+	value, exists = environment[global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL]
 	assert.True(exists)
 	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, value)
 
+	for _, label := range []string{global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL, global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL, global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL} {
+		_, exists = os.LookupEnv(label)
+		assert.False(exists)
+	}
+}
+
+func TestToEnvUseEnvironment(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing the process environment is only modified when requested.")
+
+	testFactory, _ := factory.New(global.TEST_VAR_APPLICATION_NAME)
+	testFactory.SetOutputType(global.OUTPUT_TYPE_ENV)
+	testFactory.UseEnvironment = true
+	testSerializer := New(testFactory, global.DEFAULT_PROFILE_NAME)
+	serializeErr := testSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil)
+	assert.NoError(serializeErr)
+
+	value, exists := os.LookupEnv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
+	assert.True(exists)
+	assert.Equal(global.TEST_VAR_USERNAME, value)
+
 	os.Unsetenv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
 	os.Unsetenv(global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL)
-	os.Unsetenv(global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL)
+}
+
+func TestToEnvUseEnvironmentRemovesDeletedAttributes(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing attributes removed from a profile are removed from the process environment when requested.")
+	testFactory, testSerializer, serializeErr := createTestEnv(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+	testFactory.UseEnvironment = true
+	defer testFactory.ClearEnvironment()
+	attributes := map[string]map[string]string{global.TEST_VAR_FIRST_SECTION_KEY: {global.TEST_VAR_ATTRIBUTE_NAME_LABEL: global.TEST_VAR_ATTRIBUTE_VALUE}}
+
+	assert.NoError(testSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, attributes))
+	value, exists := os.LookupEnv(global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL)
+	assert.True(exists)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, value)
+
+	assert.NoError(testSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil))
+	_, exists = os.LookupEnv(global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL)
+	assert.False(exists)
+	_, exists = os.LookupEnv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
+	assert.True(exists)
+
+	assert.NoError(testSerializer.SerializeProfile(attributes))
+	_, exists = os.LookupEnv(global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL)
+	assert.True(exists)
+	assert.NoError(testSerializer.SerializeProfile(nil))
+	_, exists = os.LookupEnv(global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL)
+	assert.False(exists)
+	_, exists = os.LookupEnv(global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL)
+	assert.True(exists)
 }
 
 func TestFromEnv(t *testing.T) {
@@ -40,10 +91,90 @@ func TestFromEnv(t *testing.T) {
 	assert.Equal(global.TEST_VAR_USERNAME, username)
 	assert.Equal(global.TEST_VAR_PASSWORD, password)
 	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+}
 
-	os.Unsetenv(global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL)
-	os.Unsetenv(global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL)
-	os.Unsetenv(global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL)
+func TestFromEnvInjected(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing deserializing from an injected environment.")
+
+	testFactory, _ := factory.New(global.TEST_VAR_APPLICATION_NAME)
+	testFactory.SetOutputType(global.OUTPUT_TYPE_ENV)
+	testFactory.SetEnvironment(map[string]string{
+		global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL:       global.TEST_VAR_USERNAME,
+		global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL:       global.TEST_VAR_PASSWORD,
+		global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL: global.TEST_VAR_ATTRIBUTE_VALUE,
+	})
+	testSerializer := New(testFactory, global.DEFAULT_PROFILE_NAME)
+
+	username, password, attributes, serializeErr := testSerializer.Deserialize()
+	assert.NoError(serializeErr)
+	assert.Equal(global.TEST_VAR_USERNAME, username)
+	assert.Equal(global.TEST_VAR_PASSWORD, password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+
+	testFactory.SetEnvironment(nil)
+	_, _, _, serializeErr = testSerializer.Deserialize()
+	assert.EqualError(serializeErr, ERR_REQUIRED_VARIABLE_USERNAME_NOT_FOUND)
+}
+
+func TestClearEnv(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing clearing a profile from the environment.")
+	testFactory, testSerializer, serializeErr := createTestEnv(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+	secondSerializer := New(testFactory, global.TEST_VAR_FIRST_PROFILE_LABEL)
+	serializeErr = secondSerializer.Serialize(global.TEST_VAR_USERNAME_ALTERNATE, global.TEST_VAR_PASSWORD_ALTERNATE, nil)
+	assert.NoError(serializeErr)
+	applyErr := testFactory.ApplyEnvironment()
+	assert.NoError(applyErr)
+
+	clearErr := testSerializer.ClearEnv()
+	assert.NoError(clearErr)
+
+	for _, label := range []string{global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL, global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL, global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL} {
+		_, exists := testFactory.GetEnvironment()[label]
+		assert.False(exists)
+		_, exists = os.LookupEnv(label)
+		assert.False(exists)
+	}
+
+	_, _, _, deserializeErr := testSerializer.Deserialize()
+	assert.EqualError(deserializeErr, ERR_REQUIRED_VARIABLE_USERNAME_NOT_FOUND)
+
+	username, _, _, deserializeErr := secondSerializer.Deserialize()
+	assert.NoError(deserializeErr)
+	assert.Equal(global.TEST_VAR_USERNAME_ALTERNATE, username)
+
+	clearErr = testFactory.ClearEnvironment()
+	assert.NoError(clearErr)
+	_, _, _, deserializeErr = secondSerializer.Deserialize()
+	assert.EqualError(deserializeErr, ERR_REQUIRED_VARIABLE_USERNAME_NOT_FOUND)
+}
+
+func TestToEnvRemovesDeletedAttributes(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing attributes removed from a profile are removed from the environment.")
+	testFactory, testSerializer, serializeErr := createTestEnv(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+
+	serializeErr = testSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil)
+	assert.NoError(serializeErr)
+	_, exists := testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL]
+	assert.False(exists)
+}
+
+func TestSerializeProfileEnv(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing saving only the profile to the environment.")
+	testFactory, testSerializer, serializeErr := createTestEnv(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+
+	serializeErr = testSerializer.SerializeProfile(nil)
+	assert.NoError(serializeErr)
+	_, exists := testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL]
+	assert.False(exists)
+	assert.Equal(global.TEST_VAR_USERNAME, testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL])
+	assert.Equal(global.TEST_VAR_PASSWORD, testFactory.GetEnvironment()[global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL])
 }
 
 func TestParseEnvironmentVariable(t *testing.T) {
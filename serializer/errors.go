@@ -3,3 +3,13 @@ package serializer
 const ERR_UNRECOGNIZED_OUTPUT_TYPE string = "sorry I do not recognize that output type, valid values are (ini)"
 const ERR_REQUIRED_VARIABLE_USERNAME_NOT_FOUND = "username has not been set via the environment and is required, load failed"
 const ERR_REQUIRED_VARIABLE_PASSWORD_NOT_FOUND = "password has not been set via the environment and is required, load failed"
+const ERR_UNRECOGNIZED_EXPORT_TYPE = "sorry I do not recognize that export type, valid values are (dotenv, posix, fish)"
+const ERR_DOTENV_INVALID_LINE = "sorry a line in the dotenv file could not be parsed, lines must be in the format KEY=value"
+const ERR_DOTENV_UNTERMINATED_QUOTE = "sorry a quoted value in the dotenv file is missing its closing quote"
+const ERR_MIGRATE_UNSUPPORTED_OUTPUT_TYPE = "sorry only file output types can be migrated, valid values are (ini, json)"
+const ERR_MIGRATE_SAME_OUTPUT_TYPE = "sorry the output type to migrate to must be different to the output type to migrate from"
+const ERR_MIGRATE_VERIFICATION_FAILED = "a migrated profile did not match the original after being saved, the original files have been restored"
+const ERR_FILE_TYPE_MISMATCH = "sorry the credentials file was written in a different output type, use Migrate to convert it before saving"
+const ERR_FORMAT_VERSION_INVALID = "sorry the format version of the file could not be read, it must be in the format major.minor"
+const ERR_FORMAT_VERSION_TOO_NEW = "sorry the file was written by a newer version of go-credentials, please upgrade to load it"
+const ERR_FORMAT_MIGRATION_MISSING = "sorry there is no way to upgrade the file from its format version, please upgrade to load it"
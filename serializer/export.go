@@ -0,0 +1,98 @@
+package serializer
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+/*
+Export is responsible for rendering a Credential/Profile combination as text that can be handed to a subprocess. The
+variables are built with the same key mapping as ToEnv (see GetEnvVariables) and are always written in sorted order.
+The supported export types are:
+
+	dotenv: KEY="value" lines, suitable for docker compose env_file or a .env file.
+	posix:  export KEY='value' lines, suitable for eval in sh, bash or zsh.
+	fish:   set -gx KEY 'value' lines, suitable for eval in fish.
+
+Shells do not allow "::" in variable names, so the posix and fish exports replace each "::" in a key with "__", e.g.
+APPLICATION_NAME__PROFILE_NAME__USERNAME. The dotenv export keeps the keys exactly as ToEnv sets them.
+*/
+func (thisSerializer *Serializer) Export(exportType string, username string, password string, attributes map[string]map[string]string) (string, error) {
+	thisSerializer.Factory.Log.Debug().Str("export_type", exportType).Msg("Exporting credential and profile.")
+	variables := thisSerializer.GetEnvVariables(username, password, attributes)
+	var builder strings.Builder
+
+	for _, key := range sortedKeys(variables) {
+		switch exportType {
+		case global.EXPORT_TYPE_DOTENV:
+			builder.WriteString(key + "=" + quoteDotEnv(variables[key]) + "\n")
+		case global.EXPORT_TYPE_POSIX:
+			builder.WriteString("export " + toShellKey(key) + "=" + quotePosix(variables[key]) + "\n")
+		case global.EXPORT_TYPE_FISH:
+			builder.WriteString("set -gx " + toShellKey(key) + " " + quoteFish(variables[key]) + "\n")
+		default:
+			thisSerializer.Factory.Log.Error().Str("unrecognized", exportType).Msg(ERR_UNRECOGNIZED_EXPORT_TYPE)
+			return "", errors.New(ERR_UNRECOGNIZED_EXPORT_TYPE)
+		}
+	}
+
+	return builder.String(), nil
+}
+
+/*
+ExportToFile writes the output of Export to fileName. As the file contains the password in plain text, it is created
+with 0600 permissions.
+*/
+func (thisSerializer *Serializer) ExportToFile(exportType string, fileName string, username string, password string, attributes map[string]map[string]string) error {
+	contents, exportErr := thisSerializer.Export(exportType, username, password, attributes)
+
+	if exportErr != nil {
+		return exportErr
+	}
+
+	writeErr := ioutil.WriteFile(fileName, []byte(contents), 0600)
+
+	if writeErr != nil {
+		thisSerializer.Factory.Log.Error().Str("file", fileName).Err(writeErr).Msg("Error writing export file.")
+		return writeErr
+	}
+
+	thisSerializer.Factory.Log.Info().Str("file", fileName).Msg("Export file saved successfully.")
+	return nil
+}
+
+func toShellKey(key string) string {
+	return strings.Replace(key, "::", "__", -1)
+}
+
+// quoteDotEnv wraps a value in double quotes, escaping the characters that dotenv parsers treat specially inside them.
+func quoteDotEnv(value string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"\"", "\\\"",
+		"$", "\\$",
+		"`", "\\`",
+		"\n", "\\n",
+		"\r", "\\r",
+	)
+
+	return "\"" + replacer.Replace(value) + "\""
+}
+
+// quotePosix wraps a value in single quotes, where nothing is special except the single quote itself.
+func quotePosix(value string) string {
+	return "'" + strings.Replace(value, "'", "'\\''", -1) + "'"
+}
+
+// quoteFish wraps a value in single quotes, where fish only treats backslash and the single quote as special.
+func quoteFish(value string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"'", "\\'",
+	)
+
+	return "'" + replacer.Replace(value) + "'"
+}
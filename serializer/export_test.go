@@ -0,0 +1,117 @@
+package serializer
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestExportDotEnv(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing exporting a profile as a dotenv file.")
+	testSerializer := createTestExport()
+
+	exported, exportErr := testSerializer.Export(global.EXPORT_TYPE_DOTENV, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD_ALTERNATE, createTestExportAttributes())
+	assert.NoError(exportErr)
+	assert.Equal(
+		global.TEST_VAR_ENVIRONMENT_ATTRIBUTE_NAME_LABEL+"=\"a global attribute value\"\n"+
+			global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL+"=\".YaJ5XAA\\${hh8^C\"\n"+
+			global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL+"=\"a_test_username\"\n",
+		exported,
+	)
+
+	exported, exportErr = testSerializer.Export(global.EXPORT_TYPE_DOTENV, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil)
+	assert.NoError(exportErr)
+	assert.Contains(exported, global.TEST_VAR_ENVIRONMENT_PASSWORD_LABEL+"=\"as=/sle\\\\sowkjg@!\"\n")
+}
+
+func TestExportPosix(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing exporting a profile as a posix script.")
+	testSerializer := createTestExport()
+
+	exported, exportErr := testSerializer.Export(global.EXPORT_TYPE_POSIX, global.TEST_VAR_USERNAME, "it's "+global.TEST_VAR_PASSWORD, nil)
+	assert.NoError(exportErr)
+	assert.Equal(
+		"export MTCA__DEFAULT__PASSWORD='it'\\''s as=/sle\\sowkjg@!'\n"+
+			"export MTCA__DEFAULT__USERNAME='a_test_username'\n",
+		exported,
+	)
+
+	shell, lookErr := exec.LookPath("sh")
+
+	if lookErr != nil {
+		log.Info().Msg("No sh found, skipping evaluation of the posix script.")
+		return
+	}
+
+	for _, password := range []string{global.TEST_VAR_PASSWORD, global.TEST_VAR_PASSWORD_ALTERNATE, "it's \"quoted\"\nand $HOME"} {
+		exported, exportErr = testSerializer.Export(global.EXPORT_TYPE_POSIX, global.TEST_VAR_USERNAME, password, nil)
+		assert.NoError(exportErr)
+		//#nosec
+		output, runErr := exec.Command(shell, "-c", exported+"printf %s \"$MTCA__DEFAULT__PASSWORD\"").Output()
+		assert.NoError(runErr)
+		assert.Equal(password, string(output))
+	}
+}
+
+func TestExportFish(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing exporting a profile as a fish script.")
+	testSerializer := createTestExport()
+
+	exported, exportErr := testSerializer.Export(global.EXPORT_TYPE_FISH, global.TEST_VAR_USERNAME, "it's "+global.TEST_VAR_PASSWORD, createTestExportAttributes())
+	assert.NoError(exportErr)
+	assert.Equal(
+		"set -gx MTCA__DEFAULT__ATTRIBUTE__FIRST_SECTION__A_TEST_ATTRIBUTE 'a global attribute value'\n"+
+			"set -gx MTCA__DEFAULT__PASSWORD 'it\\'s as=/sle\\\\sowkjg@!'\n"+
+			"set -gx MTCA__DEFAULT__USERNAME 'a_test_username'\n",
+		exported,
+	)
+}
+
+func TestExportInvalidType(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing exporting a profile with an invalid export type.")
+	testSerializer := createTestExport()
+
+	_, exportErr := testSerializer.Export(global.OUTPUT_TYPE_INVALID, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil)
+	assert.EqualError(exportErr, ERR_UNRECOGNIZED_EXPORT_TYPE)
+}
+
+func TestExportToFile(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing exporting a profile to a file.")
+	testSerializer := createTestExport()
+	exportFile := testSerializer.Factory.ParentDirectory + ".env"
+
+	exportErr := testSerializer.ExportToFile(global.EXPORT_TYPE_DOTENV, exportFile, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil)
+	assert.NoError(exportErr)
+
+	info, statErr := os.Stat(exportFile)
+	assert.NoError(statErr)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	contents, readErr := ioutil.ReadFile(exportFile)
+	assert.NoError(readErr)
+	assert.Contains(string(contents), global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL+"=\"a_test_username\"\n")
+
+	os.RemoveAll(testSerializer.Factory.ParentDirectory)
+}
+
+func createTestExport() *Serializer {
+	testFactory, _ := factory.New(global.TEST_VAR_APPLICATION_NAME)
+	return New(testFactory, global.DEFAULT_PROFILE_NAME)
+}
+
+func createTestExportAttributes() map[string]map[string]string {
+	return map[string]map[string]string{
+		global.TEST_VAR_FIRST_SECTION_KEY: {
+			global.TEST_VAR_ATTRIBUTE_NAME_LABEL: global.TEST_VAR_ATTRIBUTE_VALUE,
+		},
+	}
+}
@@ -0,0 +1,228 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/global"
+	"gopkg.in/ini.v1"
+)
+
+// formatMigration upgrades the contents of a file of fileType from one major format version to the next.
+type formatMigration func(fileType string, contents []byte) ([]byte, error)
+
+/*
+formatMigrations is the chain of upgrades applied to files written by older versions of the library, keyed by the major
+version each one upgrades from. Files written before format versions were recorded are major version 0. When the
+layout of the files changes, the major version of global.FORMAT_VERSION is increased and a migration from the previous
+major version is added here.
+*/
+var formatMigrations = map[int]formatMigration{
+	0: migrateUnversionedFormat,
+}
+
+// migrateUnversionedFormat upgrades files written before format versions were recorded, which share the 1.x layout.
+func migrateUnversionedFormat(fileType string, contents []byte) ([]byte, error) {
+	return contents, nil
+}
+
+/*
+GetFileFormatVersion returns the format version recorded in fileName. A blank string is returned if the file is
+missing, empty, or was written before format versions were recorded.
+
+Every ini and json file is stamped with global.FORMAT_VERSION when it is saved. In json files the version is the
+top-level format_version field, and in ini files it is the format_version key of the global.FORMAT_SECTION_NAME
+section, which is never treated as a profile or attribute section.
+*/
+func GetFileFormatVersion(fileName string) (string, error) {
+	fileType, detectErr := DetectFileType(fileName)
+
+	if detectErr != nil || fileType == "" {
+		return "", detectErr
+	}
+
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		return "", readErr
+	}
+
+	return readFormatVersion(fileType, contents)
+}
+
+/*
+upgradeFileFormat checks the format version of fileName before it is read or written. Files from an older major
+version are passed through formatMigrations and replaced with the current version, while files from a newer major
+version are refused with ERR_FORMAT_VERSION_TOO_NEW. A newer minor version of the current major version is only ever
+additive, so it is loaded as is. The upgraded file is written alongside the original and renamed over it (see
+WriteFileAtomic), so a process reading the shared credentials file at the same time never sees it half written.
+*/
+func upgradeFileFormat(fileName string) error {
+	fileType, detectErr := DetectFileType(fileName)
+
+	if detectErr != nil || fileType == "" {
+		return detectErr
+	}
+
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		return readErr
+	}
+
+	version, versionErr := readFormatVersion(fileType, contents)
+
+	if versionErr != nil {
+		return versionErr
+	}
+
+	fileMajor, parseErr := parseFormatMajor(version)
+
+	if parseErr != nil {
+		return parseErr
+	}
+
+	currentMajor, parseErr := parseFormatMajor(global.FORMAT_VERSION)
+
+	if parseErr != nil {
+		return parseErr
+	}
+
+	if fileMajor > currentMajor {
+		return errors.New(ERR_FORMAT_VERSION_TOO_NEW)
+	}
+
+	if fileMajor == currentMajor {
+		return nil
+	}
+
+	upgraded, upgradeErr := upgradeFormat(fileType, contents, fileMajor, currentMajor)
+
+	if upgradeErr != nil {
+		return upgradeErr
+	}
+
+	stamped, stampErr := stampFormatVersion(fileType, upgraded)
+
+	if stampErr != nil {
+		return stampErr
+	}
+
+	return WriteFileAtomic(fileName, stamped, 0600)
+}
+
+func upgradeFormat(fileType string, contents []byte, fromMajor int, toMajor int) ([]byte, error) {
+	for major := fromMajor; major < toMajor; major++ {
+		migration, exists := formatMigrations[major]
+
+		if !exists {
+			return nil, errors.New(ERR_FORMAT_MIGRATION_MISSING)
+		}
+
+		var migrateErr error
+		contents, migrateErr = migration(fileType, contents)
+
+		if migrateErr != nil {
+			return nil, migrateErr
+		}
+	}
+
+	return contents, nil
+}
+
+func readFormatVersion(fileType string, contents []byte) (string, error) {
+	if fileType == global.OUTPUT_TYPE_JSON {
+		var versioned struct {
+			FormatVersion string `json:"format_version"`
+		}
+
+		unmarshalErr := json.Unmarshal(contents, &versioned)
+		return versioned.FormatVersion, unmarshalErr
+	}
+
+	iniFile, loadErr := ini.Load(contents)
+
+	if loadErr != nil {
+		return "", loadErr
+	}
+
+	formatSection, sectionErr := iniFile.GetSection(global.FORMAT_SECTION_NAME)
+
+	if sectionErr != nil {
+		return "", nil
+	}
+
+	return formatSection.Key(global.FORMAT_VERSION_LABEL).String(), nil
+}
+
+func stampFormatVersion(fileType string, contents []byte) ([]byte, error) {
+	if fileType == global.OUTPUT_TYPE_JSON {
+		var fields map[string]json.RawMessage
+		unmarshalErr := json.Unmarshal(contents, &fields)
+
+		if unmarshalErr != nil {
+			return nil, unmarshalErr
+		}
+
+		if fields == nil {
+			fields = make(map[string]json.RawMessage)
+		}
+
+		versionJson, marshalErr := json.Marshal(global.FORMAT_VERSION)
+
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+
+		fields[global.FORMAT_VERSION_LABEL] = versionJson
+		return json.MarshalIndent(fields, "", global.INDENT_JSON)
+	}
+
+	iniFile, loadErr := ini.Load(contents)
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	setIniFormatVersion(iniFile)
+	var stamped bytes.Buffer
+	_, writeErr := iniFile.WriteTo(&stamped)
+
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	return stamped.Bytes(), nil
+}
+
+func setIniFormatVersion(iniFile *ini.File) {
+	iniFile.Section(global.FORMAT_SECTION_NAME).Key(global.FORMAT_VERSION_LABEL).SetValue(global.FORMAT_VERSION)
+}
+
+// parseFormatMajor returns the major part of a major.minor format version, with a blank version being major 0.
+func parseFormatMajor(version string) (int, error) {
+	if version == "" {
+		return 0, nil
+	}
+
+	parts := strings.Split(version, ".")
+
+	if len(parts) != 2 {
+		return 0, errors.New(ERR_FORMAT_VERSION_INVALID)
+	}
+
+	major, majorErr := strconv.Atoi(parts[0])
+	minor, minorErr := strconv.Atoi(parts[1])
+
+	if majorErr != nil || minorErr != nil || major < 0 || minor < 0 {
+		return 0, errors.New(ERR_FORMAT_VERSION_INVALID)
+	}
+
+	return major, nil
+}
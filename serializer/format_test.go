@@ -0,0 +1,143 @@
+package serializer
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestFormatVersionStamped(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing the format version is written to every file.")
+
+	for _, fileType := range GetSupportedFileTypes() {
+		log.Info().Msgf("Testing the '%v' file type.", fileType)
+		testFactory, testSerializer, serializeErr := createTestIni(global.DEFAULT_PROFILE_NAME, false)
+
+		if fileType == global.OUTPUT_TYPE_JSON {
+			os.RemoveAll(testFactory.ParentDirectory)
+			testFactory, testSerializer, serializeErr = createTestJson(global.DEFAULT_PROFILE_NAME, false)
+		}
+
+		assert.NoError(serializeErr)
+
+		for _, fileName := range []string{testFactory.CredentialFile, testSerializer.ConfigFile} {
+			version, versionErr := GetFileFormatVersion(fileName)
+			assert.NoError(versionErr)
+			assert.Equal(global.FORMAT_VERSION, version)
+		}
+
+		profiles, listErr := ListProfiles(testFactory)
+		assert.NoError(listErr)
+		assert.Equal([]string{global.DEFAULT_PROFILE_NAME}, profiles)
+
+		_, _, attributes, deserializeErr := testSerializer.Deserialize()
+		assert.NoError(deserializeErr)
+		assert.NotContains(attributes, global.FORMAT_SECTION_NAME)
+
+		os.RemoveAll(testFactory.ParentDirectory)
+	}
+}
+
+func TestFormatVersionUpgrade(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing files written before format versions are upgraded in place.")
+	testSerializer := createTestExport()
+
+	credentialContents := "[default]\nusername = " + global.TEST_VAR_USERNAME + "\npassword = " + global.TEST_VAR_PASSWORD + "\n"
+	writeErr := ioutil.WriteFile(testSerializer.CredentialFile, []byte(credentialContents), 0600)
+	assert.NoError(writeErr)
+	profileContents := "{\"attributes\": {\"" + global.TEST_VAR_FIRST_SECTION_KEY + "\": {\"" + global.TEST_VAR_ATTRIBUTE_NAME_LABEL + "\": \"" + global.TEST_VAR_ATTRIBUTE_VALUE + "\"}}}"
+	writeErr = ioutil.WriteFile(testSerializer.ConfigFile, []byte(profileContents), 0600)
+	assert.NoError(writeErr)
+
+	username, password, attributes, deserializeErr := testSerializer.Deserialize()
+	assert.NoError(deserializeErr)
+	assert.Equal(global.TEST_VAR_USERNAME, username)
+	assert.Equal(global.TEST_VAR_PASSWORD, password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+
+	for _, fileName := range []string{testSerializer.CredentialFile, testSerializer.ConfigFile} {
+		version, versionErr := GetFileFormatVersion(fileName)
+		assert.NoError(versionErr)
+		assert.Equal(global.FORMAT_VERSION, version)
+	}
+
+	detectedType, detectErr := DetectFileType(testSerializer.ConfigFile)
+	assert.NoError(detectErr)
+	assert.Equal(global.OUTPUT_TYPE_JSON, detectedType)
+
+	// The upgraded files are renamed over the originals, so no temporary files are left behind.
+	parentFiles, readErr := ioutil.ReadDir(testSerializer.Factory.ParentDirectory)
+	assert.NoError(readErr)
+
+	for _, parentFile := range parentFiles {
+		assert.False(strings.HasSuffix(parentFile.Name(), ".tmp"), parentFile.Name())
+	}
+
+	os.RemoveAll(testSerializer.Factory.ParentDirectory)
+}
+
+func TestFormatVersionRefused(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing files from a newer major version are refused.")
+	testFactory, testSerializer, serializeErr := createTestJson(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+
+	expectedErrors := map[string]string{
+		"1.99":  "",
+		"99.0":  ERR_FORMAT_VERSION_TOO_NEW,
+		"1":     ERR_FORMAT_VERSION_INVALID,
+		"one.0": ERR_FORMAT_VERSION_INVALID,
+	}
+
+	for version, expectedErr := range expectedErrors {
+		credentialContents := []byte("{\"format_version\": \"" + version + "\", \"credentials\": {}}")
+		writeErr := ioutil.WriteFile(testFactory.CredentialFile, credentialContents, 0600)
+		assert.NoError(writeErr)
+
+		_, _, _, deserializeErr := testSerializer.Deserialize()
+		serializeErr = testSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil)
+
+		if expectedErr == "" {
+			assert.NoError(deserializeErr)
+			assert.NoError(serializeErr)
+			continue
+		}
+
+		assert.EqualError(deserializeErr, expectedErr)
+		assert.EqualError(serializeErr, expectedErr)
+		unchangedContents, readErr := ioutil.ReadFile(testFactory.CredentialFile)
+		assert.NoError(readErr)
+		assert.Equal(credentialContents, unchangedContents)
+	}
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
+func TestFormatMigrationChain(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing format migrations are applied in order.")
+	originalMigrations := formatMigrations
+	defer func() { formatMigrations = originalMigrations }()
+
+	formatMigrations = map[int]formatMigration{
+		0: func(fileType string, contents []byte) ([]byte, error) { return append(contents, '1'), nil },
+		1: func(fileType string, contents []byte) ([]byte, error) { return append(contents, '2'), nil },
+		3: func(fileType string, contents []byte) ([]byte, error) { return nil, errors.New(fileType) },
+	}
+
+	upgraded, upgradeErr := upgradeFormat(global.OUTPUT_TYPE_INI, []byte("0"), 0, 2)
+	assert.NoError(upgradeErr)
+	assert.Equal("012", string(upgraded))
+
+	_, upgradeErr = upgradeFormat(global.OUTPUT_TYPE_INI, []byte("0"), 1, 3)
+	assert.EqualError(upgradeErr, ERR_FORMAT_MIGRATION_MISSING)
+
+	_, upgradeErr = upgradeFormat(global.OUTPUT_TYPE_JSON, []byte("0"), 3, 4)
+	assert.EqualError(upgradeErr, global.OUTPUT_TYPE_JSON)
+}
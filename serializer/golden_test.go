@@ -0,0 +1,99 @@
+package serializer
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata with the current output")
+
+func TestGoldenFiles(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing serialized output matches the golden files.")
+
+	for _, fileType := range GetSupportedFileTypes() {
+		log.Info().Msgf("Testing the '%v' file type.", fileType)
+		testFactory, _ := factory.New(global.TEST_VAR_APPLICATION_NAME)
+		testFactory.SetOutputType(fileType)
+
+		// Saving repeatedly must not shuffle the output, so each profile is saved a few times.
+		for i := 0; i < 5; i++ {
+			for _, profileName := range []string{global.DEFAULT_PROFILE_NAME, global.TEST_VAR_FIRST_PROFILE_LABEL} {
+				serializeErr := New(testFactory, profileName).Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, createGoldenAttributes())
+				assert.NoError(serializeErr)
+			}
+		}
+
+		assertGoldenFile(t, testFactory.CredentialFile, "credentials."+fileType+".golden")
+		assertGoldenFile(t, New(testFactory, global.DEFAULT_PROFILE_NAME).ConfigFile, "config."+fileType+".golden")
+		os.RemoveAll(testFactory.ParentDirectory)
+	}
+
+	testSerializer := createTestExport()
+
+	for _, exportType := range []string{global.EXPORT_TYPE_DOTENV, global.EXPORT_TYPE_POSIX, global.EXPORT_TYPE_FISH} {
+		exported, exportErr := testSerializer.Export(exportType, global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, createGoldenAttributes())
+		assert.NoError(exportErr)
+		assertGoldenContents(t, []byte(exported), "export."+exportType+".golden")
+	}
+
+	os.RemoveAll(testSerializer.Factory.ParentDirectory)
+}
+
+func assertGoldenFile(t *testing.T, fileName string, goldenName string) {
+	//#nosec
+	contents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	assertGoldenContents(t, contents, goldenName)
+}
+
+func assertGoldenContents(t *testing.T, contents []byte, goldenName string) {
+	goldenFile := filepath.Join("testdata", goldenName)
+
+	if *updateGolden {
+		writeErr := ioutil.WriteFile(goldenFile, contents, 0600)
+
+		if writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+
+	//#nosec
+	expected, readErr := ioutil.ReadFile(goldenFile)
+
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	if string(expected) != string(contents) {
+		t.Errorf("%s does not match the golden file, run go test with -update if the change is expected\n--- expected\n%s\n--- actual\n%s", goldenName, expected, contents)
+	}
+}
+
+func createGoldenAttributes() map[string]map[string]string {
+	return map[string]map[string]string{
+		global.NO_SECTION_KEY: {
+			"zeta":  "last",
+			"alpha": "first",
+		},
+		global.TEST_VAR_SECOND_SECTION_KEY: {
+			"region":   "ap-southeast-2",
+			"endpoint": "https://example.com",
+			"timeout":  "30",
+		},
+		global.TEST_VAR_FIRST_SECTION_KEY: {
+			global.TEST_VAR_ATTRIBUTE_NAME_LABEL: global.TEST_VAR_ATTRIBUTE_VALUE,
+			"another_key":                        "another value",
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package serializer
 
 import (
+	"bytes"
+	"github.com/engi-fyi/go-credentials/global"
 	"gopkg.in/ini.v1"
 	"os"
 )
@@ -9,6 +11,9 @@ import (
 ToIni is responsible for serializing a Credential and Profile to an ini file. Attribute sections are directly
 translatable to sections in the Profile. Username and Password will have an appropriate label (either the default or an
 alternate set in the Credential's related Factory.
+
+The existing config file is updated rather than started afresh, so comments and sections added by hand are kept. New
+sections and keys are added in alphabetical order. Both files are replaced with WriteFileAtomic.
 */
 func (thisSerializer *Serializer) ToIni(username string, password string, attributes map[string]map[string]string) error {
 	thisSerializer.Factory.Log.Info().Msg("Serializing credential and profile to ini file.")
@@ -38,12 +43,13 @@ func (thisSerializer *Serializer) saveCredentialIni(username string, password st
 		return credIniError
 	}
 
+	setIniFormatVersion(credentialIni)
 	thisSerializer.Factory.Log.Trace().Msg("Adding username and password to credentials file.")
 	credentialIni.Section(thisSerializer.ProfileName).Key(usernameKey).SetValue(username)
 	credentialIni.Section(thisSerializer.ProfileName).Key(passwordKey).SetValue(password)
 
 	thisSerializer.Factory.Log.Info().Msg("Saving credential ini file.")
-	saveErr := credentialIni.SaveTo(thisSerializer.CredentialFile)
+	saveErr := saveIniAtomic(credentialIni, thisSerializer.CredentialFile)
 
 	if saveErr != nil {
 		thisSerializer.Factory.Log.Error().Str("file", thisSerializer.CredentialFile).Err(saveErr).Msg("Error saving ini file.")
@@ -54,12 +60,23 @@ func (thisSerializer *Serializer) saveCredentialIni(username string, password st
 	return nil
 }
 
+/*
+saveProfileIni applies attributes to the existing config file rather than replacing it, so comments, key order and
+sections the Profile does not know about are kept. Keys that are no longer in a section of attributes are removed.
+*/
 func (thisSerializer *Serializer) saveProfileIni(attributes map[string]map[string]string) error {
 	thisSerializer.Factory.Log.Trace().Msg("Serializing profile to ini file.")
-	profileIni := ini.Empty()
+	profileIni, initErr := thisSerializer.initProfileIni()
+
+	if initErr != nil {
+		return initErr
+	}
+
+	setIniFormatVersion(profileIni)
 
 	thisSerializer.Factory.Log.Trace().Msg("Processing attributes.")
-	for key, value := range attributes {
+	for _, key := range sortedSectionNames(attributes) {
+		value := attributes[key]
 		thisSerializer.Factory.Log.Trace().Str("attribute", key).Msg("Adding section.")
 		mySection, sectionErr := profileIni.NewSection(key)
 
@@ -67,9 +84,16 @@ func (thisSerializer *Serializer) saveProfileIni(attributes map[string]map[strin
 			return sectionErr
 		}
 
-		for subKey, subValue := range value {
+		for _, existingKey := range mySection.KeyStrings() {
+			if _, exists := value[existingKey]; !exists {
+				thisSerializer.Factory.Log.Trace().Str("attribute", existingKey).Msg("Removing attribute.")
+				mySection.DeleteKey(existingKey)
+			}
+		}
+
+		for _, subKey := range sortedKeys(value) {
 			thisSerializer.Factory.Log.Trace().Str("attribute", subKey).Msg("Adding attribute.")
-			_, keyErr := mySection.NewKey(subKey, subValue)
+			_, keyErr := mySection.NewKey(subKey, value[subKey])
 
 			if keyErr != nil {
 				return keyErr
@@ -78,7 +102,7 @@ func (thisSerializer *Serializer) saveProfileIni(attributes map[string]map[strin
 	}
 
 	thisSerializer.Factory.Log.Info().Msg("Saving profile ini file.")
-	saveErr := profileIni.SaveTo(thisSerializer.ConfigFile)
+	saveErr := saveIniAtomic(profileIni, thisSerializer.ConfigFile)
 
 	if saveErr != nil {
 		return saveErr
@@ -88,6 +112,32 @@ func (thisSerializer *Serializer) saveProfileIni(attributes map[string]map[strin
 	return nil
 }
 
+// initProfileIni loads the existing config file to save over, starting afresh if it was written in another format.
+func (thisSerializer *Serializer) initProfileIni() (*ini.File, error) {
+	configType, detectErr := DetectFileType(thisSerializer.ConfigFile)
+
+	if detectErr != nil {
+		return nil, detectErr
+	}
+
+	if configType == global.OUTPUT_TYPE_JSON {
+		return ini.Empty(), nil
+	}
+
+	return initIni(thisSerializer.ConfigFile)
+}
+
+// saveIniAtomic writes iniFile to fileName with WriteFileAtomic, as the credentials file is shared by every profile.
+func saveIniAtomic(iniFile *ini.File, fileName string) error {
+	var buffer bytes.Buffer
+
+	if _, writeErr := iniFile.WriteTo(&buffer); writeErr != nil {
+		return writeErr
+	}
+
+	return WriteFileAtomic(fileName, buffer.Bytes(), 0600)
+}
+
 func initIni(fileName string) (*ini.File, error) {
 	if _, statErr := os.Stat(fileName); os.IsNotExist(statErr) {
 		emptyFile, emptyErr := os.Create(fileName)
@@ -103,6 +153,12 @@ func initIni(fileName string) (*ini.File, error) {
 		}
 	}
 
+	upgradeErr := upgradeFileFormat(fileName)
+
+	if upgradeErr != nil {
+		return nil, upgradeErr
+	}
+
 	return ini.Load(fileName)
 }
 
@@ -152,6 +208,10 @@ func (thisSerializer *Serializer) loadProfileIni() (map[string]map[string]string
 	}
 
 	for _, section := range profileIni.Sections() {
+		if section.Name() == global.FORMAT_SECTION_NAME {
+			continue
+		}
+
 		keys := section.KeysHash()
 		myAttributes[section.Name()] = keys
 	}
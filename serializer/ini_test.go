@@ -3,7 +3,9 @@ package serializer
 import (
 	"github.com/engi-fyi/go-credentials/factory"
 	"github.com/engi-fyi/go-credentials/global"
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -37,6 +39,48 @@ func TestFromIni(t *testing.T) {
 	os.RemoveAll(testFactory.ParentDirectory)
 }
 
+func TestToIniPreservesConfig(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing hand edits to the config file are kept when saving.")
+	testFactory, testSerializer, serializeErr := createTestIni(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+
+	handEdited := "; settings for the default profile\n" +
+		"[" + global.TEST_VAR_FIRST_SECTION_KEY + "]\n" +
+		"; kept up to date by hand\n" +
+		global.TEST_VAR_ATTRIBUTE_NAME_LABEL + " = " + global.TEST_VAR_ATTRIBUTE_VALUE + "\n" +
+		"removed_key = removed_value\n\n" +
+		"; not managed by the profile\n" +
+		"[unknown_section]\n" +
+		"unknown_key = unknown_value\n"
+	writeErr := ioutil.WriteFile(testSerializer.ConfigFile, []byte(handEdited), 0600)
+	assert.NoError(writeErr)
+
+	serializeErr = testSerializer.Serialize(global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, map[string]map[string]string{
+		global.TEST_VAR_FIRST_SECTION_KEY: {
+			global.TEST_VAR_ATTRIBUTE_NAME_LABEL: global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED,
+		},
+	})
+	assert.NoError(serializeErr)
+
+	configContents, readErr := ioutil.ReadFile(testSerializer.ConfigFile)
+	assert.NoError(readErr)
+	config := string(configContents)
+	assert.Contains(config, "; settings for the default profile")
+	assert.Contains(config, "; kept up to date by hand")
+	assert.Contains(config, "; not managed by the profile")
+	assert.Contains(config, global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED)
+	assert.NotContains(config, "removed_key")
+	assert.Less(strings.Index(config, global.TEST_VAR_FIRST_SECTION_KEY), strings.Index(config, "unknown_section"))
+
+	_, _, attributes, deserializeErr := testSerializer.Deserialize()
+	assert.NoError(deserializeErr)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE_CHANGED, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+	assert.Equal("unknown_value", attributes["unknown_section"]["unknown_key"])
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
 func createTestIni(profileName string, useAlternates bool) (*factory.Factory, *Serializer, error) {
 	testFactory, _ := factory.New(global.TEST_VAR_APPLICATION_NAME)
 	testSerializer := New(testFactory, profileName)
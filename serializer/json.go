@@ -1,6 +1,7 @@
 package serializer
 
 import (
+	"bytes"
 	"encoding/json"
 	"github.com/engi-fyi/go-credentials/global"
 	"io/ioutil"
@@ -37,6 +38,7 @@ func (thisSerializer *Serializer) saveCredentialJson(username string, password s
 		return initErr
 	}
 
+	existingCredential.FormatVersion = global.FORMAT_VERSION
 	existingCredential.Credentials[thisSerializer.ProfileName] = serializedCredentials{
 		Username: username,
 		Password: password,
@@ -48,7 +50,7 @@ func (thisSerializer *Serializer) saveCredentialJson(username string, password s
 		return marshalErr
 	}
 
-	writeErr := ioutil.WriteFile(thisSerializer.CredentialFile, outJson, 0600)
+	writeErr := WriteFileAtomic(thisSerializer.CredentialFile, outJson, 0600)
 
 	if writeErr != nil {
 		return writeErr
@@ -60,12 +62,25 @@ func (thisSerializer *Serializer) saveCredentialJson(username string, password s
 
 func (thisSerializer *Serializer) saveProfileJson(attributes map[string]map[string]string) error {
 	thisSerializer.Factory.Log.Trace().Msg("Serializing profile to json file.")
-	existingProfile, initErr := initJsonProfile(thisSerializer.CredentialFile)
+	configType, detectErr := DetectFileType(thisSerializer.ConfigFile)
 
-	if initErr != nil {
-		return initErr
+	if detectErr != nil {
+		return detectErr
+	}
+
+	// A config file written as ini is replaced in full, so it is not read.
+	existingProfile := &profileSerializer{}
+
+	if configType != global.OUTPUT_TYPE_INI {
+		var initErr error
+		existingProfile, initErr = initJsonProfile(thisSerializer.ConfigFile)
+
+		if initErr != nil {
+			return initErr
+		}
 	}
 
+	existingProfile.FormatVersion = global.FORMAT_VERSION
 	existingProfile.Attributes = attributes
 	outJson, marshalErr := json.MarshalIndent(existingProfile, "", global.INDENT_JSON)
 
@@ -73,7 +88,7 @@ func (thisSerializer *Serializer) saveProfileJson(attributes map[string]map[stri
 		return marshalErr
 	}
 
-	writeErr := ioutil.WriteFile(thisSerializer.ConfigFile, outJson, 0600)
+	writeErr := WriteFileAtomic(thisSerializer.ConfigFile, outJson, 0600)
 
 	if writeErr != nil {
 		return writeErr
@@ -104,6 +119,12 @@ func initJson(fileName string) ([]byte, error) {
 		}
 	}
 
+	upgradeErr := upgradeFileFormat(fileName)
+
+	if upgradeErr != nil {
+		return []byte{}, upgradeErr
+	}
+
 	//#nosec
 	inJson, readErr := ioutil.ReadFile(fileName)
 
@@ -111,6 +132,10 @@ func initJson(fileName string) ([]byte, error) {
 		return []byte{}, readErr
 	}
 
+	if len(bytes.TrimSpace(inJson)) == 0 {
+		return []byte("{}"), nil
+	}
+
 	return inJson, nil
 }
 
@@ -23,47 +23,126 @@ func New(sourceFactory *factory.Factory, profileName string) *Serializer {
 Serialize is responsible for serializing an Credential and Profile, determining the file output type based on the value
 of thisSerializer.Factory.OutputType. It is possible to serialize into multiple formats by initiating new factories, but
 there is only one version of config with no extension. Every time a Serialize call is made, the file contents are
-overwritten with the new values. Two formats cannot exist together.
+overwritten with the new values, except for ini config files where comments and unknown sections are kept (see ToIni).
+Two formats cannot exist together.
 
 The one exception to these rules is Environment, which doesn't save settings to file, although won't persists between
 sessions.
+
+Before writing a file type, the existing files are checked (see DetectFileType). If the credentials file, which is
+shared by every profile, was written in a different format then ERR_FILE_TYPE_MISMATCH is returned rather than
+overwriting the other profiles; use Migrate to convert it first.
 */
 func (thisSerializer *Serializer) Serialize(username string, password string, attributes map[string]map[string]string) error {
-	thisSerializer.Factory.Log.Debug().Str("output_type", thisSerializer.Factory.OutputType).Msg("Serializing credential and profile.")
+	return thisSerializer.serializeAs(thisSerializer.Factory.OutputType, username, password, attributes)
+}
+
+func (thisSerializer *Serializer) serializeAs(outputType string, username string, password string, attributes map[string]map[string]string) error {
+	thisSerializer.Factory.Log.Debug().Str("output_type", outputType).Msg("Serializing credential and profile.")
+
+	if outputType == global.OUTPUT_TYPE_INI || outputType == global.OUTPUT_TYPE_JSON {
+		typeErr := thisSerializer.checkFileTypes(outputType)
 
-	if thisSerializer.Factory.OutputType == global.OUTPUT_TYPE_INI {
+		if typeErr != nil {
+			return typeErr
+		}
+	}
+
+	if outputType == global.OUTPUT_TYPE_INI {
 		return thisSerializer.ToIni(username, password, attributes)
-	} else if thisSerializer.Factory.OutputType == global.OUTPUT_TYPE_ENV {
+	} else if outputType == global.OUTPUT_TYPE_ENV {
 		return thisSerializer.ToEnv(username, password, attributes)
-	} else if thisSerializer.Factory.OutputType == global.OUTPUT_TYPE_JSON {
+	} else if outputType == global.OUTPUT_TYPE_JSON {
 		return thisSerializer.ToJson(username, password, attributes)
+	} else if outputType == global.OUTPUT_TYPE_AWS {
+		return thisSerializer.ToAws(username, password, attributes)
 	} else {
-		thisSerializer.Factory.Log.Error().Str("unrecognized", thisSerializer.Factory.OutputType).Msg(ERR_UNRECOGNIZED_OUTPUT_TYPE)
+		thisSerializer.Factory.Log.Error().Str("unrecognized", outputType).Msg(ERR_UNRECOGNIZED_OUTPUT_TYPE)
+		return errors.New(ERR_UNRECOGNIZED_OUTPUT_TYPE)
+	}
+}
+
+/*
+SerializeProfile saves only the Profile's attributes, leaving the credentials file (or the username and password
+variables for env) untouched. It follows the same rules as Serialize, and is used when the username and password have
+not changed since they were last saved.
+*/
+func (thisSerializer *Serializer) SerializeProfile(attributes map[string]map[string]string) error {
+	outputType := thisSerializer.Factory.OutputType
+	thisSerializer.Factory.Log.Debug().Str("output_type", outputType).Msg("Serializing profile.")
+
+	if outputType == global.OUTPUT_TYPE_INI || outputType == global.OUTPUT_TYPE_JSON {
+		typeErr := thisSerializer.checkFileTypes(outputType)
+
+		if typeErr != nil {
+			return typeErr
+		}
+	}
+
+	if outputType == global.OUTPUT_TYPE_INI {
+		return thisSerializer.saveProfileIni(attributes)
+	} else if outputType == global.OUTPUT_TYPE_ENV {
+		return thisSerializer.toProfileEnv(attributes)
+	} else if outputType == global.OUTPUT_TYPE_JSON {
+		return thisSerializer.saveProfileJson(attributes)
+	} else if outputType == global.OUTPUT_TYPE_AWS {
+		username, password, loadErr := thisSerializer.loadAwsCredential()
+
+		if loadErr != nil {
+			return loadErr
+		}
+
+		return thisSerializer.ToAws(username, password, attributes)
+	} else {
+		thisSerializer.Factory.Log.Error().Str("unrecognized", outputType).Msg(ERR_UNRECOGNIZED_OUTPUT_TYPE)
 		return errors.New(ERR_UNRECOGNIZED_OUTPUT_TYPE)
 	}
 }
 
 /*
 Deserialize is responsible for deserializing an Credential and Profile, determining the file input type based on the
-value of thisSerializer.Factory.OutputType.
+value of thisSerializer.Factory.OutputType. For the file types, the format of the credentials file and config file are
+detected from their contents (see DetectFileType), so a profile written as json can still be loaded by a Factory set
+to ini and vice versa. OutputType is only used when a file is missing or empty.
 
 For the format expected of each file, please see the appropriate From<Type> function.
 */
 func (thisSerializer *Serializer) Deserialize() (string, string, map[string]map[string]string, error) {
-	thisSerializer.Factory.Log.Debug().Str("output_type", thisSerializer.Factory.OutputType).Msg("Deserializing credential and profile.")
+	return thisSerializer.deserializeAs(thisSerializer.Factory.OutputType)
+}
+
+func (thisSerializer *Serializer) deserializeAs(outputType string) (string, string, map[string]map[string]string, error) {
+	thisSerializer.Factory.Log.Debug().Str("output_type", outputType).Msg("Deserializing credential and profile.")
 
-	if thisSerializer.Factory.OutputType == global.OUTPUT_TYPE_INI {
-		return thisSerializer.FromIni()
-	} else if thisSerializer.Factory.OutputType == global.OUTPUT_TYPE_ENV {
+	if outputType == global.OUTPUT_TYPE_INI || outputType == global.OUTPUT_TYPE_JSON {
+		return thisSerializer.deserializeFile(outputType)
+	} else if outputType == global.OUTPUT_TYPE_ENV {
 		return thisSerializer.FromEnv()
-	} else if thisSerializer.Factory.OutputType == global.OUTPUT_TYPE_JSON {
-		return thisSerializer.FromJson()
+	} else if outputType == global.OUTPUT_TYPE_AWS {
+		return thisSerializer.FromAws()
 	} else {
-		thisSerializer.Factory.Log.Error().Str("unrecognized", thisSerializer.Factory.OutputType).Msg(ERR_UNRECOGNIZED_OUTPUT_TYPE)
+		thisSerializer.Factory.Log.Error().Str("unrecognized", outputType).Msg(ERR_UNRECOGNIZED_OUTPUT_TYPE)
 		return "", "", make(map[string]map[string]string), errors.New(ERR_UNRECOGNIZED_OUTPUT_TYPE)
 	}
 }
 
+/*
+GetStorageFiles returns the files that the profile is stored in with the Factory's current output type: the credentials
+file and config file for ini and json, the AWS files for aws (see GetAwsFiles), and none for env, which is kept in
+memory. The files may not exist yet.
+*/
+func (thisSerializer *Serializer) GetStorageFiles() ([]string, error) {
+	switch thisSerializer.Factory.OutputType {
+	case global.OUTPUT_TYPE_ENV:
+		return nil, nil
+	case global.OUTPUT_TYPE_AWS:
+		credentialFile, configFile, filesErr := GetAwsFiles(thisSerializer.Factory)
+		return []string{credentialFile, configFile}, filesErr
+	}
+
+	return []string{thisSerializer.CredentialFile, thisSerializer.ConfigFile}, nil
+}
+
 func GetSupportedFileTypes() []string {
 	return []string{
 		global.OUTPUT_TYPE_INI,
@@ -0,0 +1,220 @@
+package serializer
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+
+	"github.com/engi-fyi/go-credentials/factory"
+)
+
+/*
+MigrateOptions changes the behaviour of MigrateWithOptions.
+
+Backup: keep a copy of each file in the old format next to the original, named <file>.<fromType>.bak.
+*/
+type MigrateOptions struct {
+	Backup bool
+}
+
+type migratedProfile struct {
+	username   string
+	password   string
+	attributes map[string]map[string]string
+}
+
+/*
+Migrate converts every profile stored by sourceFactory from fromType to toType, without keeping a backup. See
+MigrateWithOptions.
+*/
+func Migrate(sourceFactory *factory.Factory, fromType string, toType string) error {
+	return MigrateWithOptions(sourceFactory, fromType, toType, MigrateOptions{})
+}
+
+/*
+MigrateWithOptions converts every profile stored by sourceFactory from fromType to toType. Only the file output types
+(see GetSupportedFileTypes) can be migrated.
+
+Every profile is loaded in the old format before anything is written. As both formats use the same file names, the old
+files are then removed and each profile is written in the new format and read back to verify the round trip. If any
+profile fails to save or verify, the files are restored to exactly what they were before the migration started and an
+error is returned. Once the migration succeeds, the OutputType of sourceFactory is set to toType.
+*/
+func MigrateWithOptions(sourceFactory *factory.Factory, fromType string, toType string, options MigrateOptions) error {
+	sourceFactory.Log.Info().Str("from", fromType).Str("to", toType).Msg("Migrating profiles.")
+
+	if !isSupportedFileType(fromType) || !isSupportedFileType(toType) {
+		sourceFactory.Log.Error().Str("from", fromType).Str("to", toType).Msg(ERR_MIGRATE_UNSUPPORTED_OUTPUT_TYPE)
+		return errors.New(ERR_MIGRATE_UNSUPPORTED_OUTPUT_TYPE)
+	}
+
+	if fromType == toType {
+		sourceFactory.Log.Error().Str("output_type", fromType).Msg(ERR_MIGRATE_SAME_OUTPUT_TYPE)
+		return errors.New(ERR_MIGRATE_SAME_OUTPUT_TYPE)
+	}
+
+	profileNames, listErr := listProfilesAs(sourceFactory, fromType)
+
+	if listErr != nil {
+		return listErr
+	}
+
+	profiles := make(map[string]migratedProfile)
+
+	for _, profileName := range profileNames {
+		username, password, attributes, loadErr := New(sourceFactory, profileName).deserializeAs(fromType)
+
+		if loadErr != nil {
+			sourceFactory.Log.Error().Err(loadErr).Str("profile", profileName).Msg("Error loading profile to migrate.")
+			return loadErr
+		}
+
+		profiles[profileName] = migratedProfile{username, password, attributes}
+	}
+
+	fileNames := []string{sourceFactory.CredentialFile}
+
+	for _, profileName := range profileNames {
+		fileNames = append(fileNames, New(sourceFactory, profileName).ConfigFile)
+	}
+
+	originals, readErr := readMigrationFiles(fileNames)
+
+	if readErr != nil {
+		return readErr
+	}
+
+	if options.Backup {
+		for fileName, contents := range originals {
+			sourceFactory.Log.Trace().Str("file", fileName).Msg("Backing up file.")
+			backupErr := ioutil.WriteFile(fileName+"."+fromType+".bak", contents, 0600)
+
+			if backupErr != nil {
+				return backupErr
+			}
+		}
+	}
+
+	migrateErr := writeMigratedProfiles(sourceFactory, toType, profileNames, profiles, originals)
+
+	if migrateErr != nil {
+		sourceFactory.Log.Error().Err(migrateErr).Msg("Migration failed, restoring original files.")
+		restoreErr := restoreMigrationFiles(fileNames, originals)
+
+		if restoreErr != nil {
+			return restoreErr
+		}
+
+		return migrateErr
+	}
+
+	sourceFactory.Log.Info().Int("profiles", len(profileNames)).Msg("Migration complete.")
+	return sourceFactory.SetOutputType(toType)
+}
+
+func writeMigratedProfiles(sourceFactory *factory.Factory, toType string, profileNames []string, profiles map[string]migratedProfile, originals map[string][]byte) error {
+	for fileName := range originals {
+		removeErr := os.Remove(fileName)
+
+		if removeErr != nil {
+			return removeErr
+		}
+	}
+
+	for _, profileName := range profileNames {
+		myProfile := profiles[profileName]
+		saveErr := New(sourceFactory, profileName).serializeAs(toType, myProfile.username, myProfile.password, myProfile.attributes)
+
+		if saveErr != nil {
+			return saveErr
+		}
+	}
+
+	for _, profileName := range profileNames {
+		username, password, attributes, loadErr := New(sourceFactory, profileName).deserializeAs(toType)
+
+		if loadErr != nil {
+			return loadErr
+		}
+
+		myProfile := profiles[profileName]
+
+		if username != myProfile.username ||
+			password != myProfile.password ||
+			!reflect.DeepEqual(withoutEmptySections(attributes), withoutEmptySections(myProfile.attributes)) {
+			sourceFactory.Log.Error().Str("profile", profileName).Msg(ERR_MIGRATE_VERIFICATION_FAILED)
+			return errors.New(ERR_MIGRATE_VERIFICATION_FAILED)
+		}
+	}
+
+	return nil
+}
+
+func readMigrationFiles(fileNames []string) (map[string][]byte, error) {
+	originals := make(map[string][]byte)
+
+	for _, fileName := range fileNames {
+		if _, statErr := os.Stat(fileName); os.IsNotExist(statErr) {
+			continue
+		}
+
+		//#nosec
+		contents, readErr := ioutil.ReadFile(fileName)
+
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		originals[fileName] = contents
+	}
+
+	return originals, nil
+}
+
+// restoreMigrationFiles puts back the original contents of each file, removing any that did not exist before.
+func restoreMigrationFiles(fileNames []string, originals map[string][]byte) error {
+	for _, fileName := range fileNames {
+		contents, existed := originals[fileName]
+
+		if !existed {
+			removeErr := os.Remove(fileName)
+
+			if removeErr != nil && !os.IsNotExist(removeErr) {
+				return removeErr
+			}
+
+			continue
+		}
+
+		writeErr := WriteFileAtomic(fileName, contents, 0600)
+
+		if writeErr != nil {
+			return writeErr
+		}
+	}
+
+	return nil
+}
+
+func withoutEmptySections(attributes map[string]map[string]string) map[string]map[string]string {
+	nonEmpty := make(map[string]map[string]string)
+
+	for section, values := range attributes {
+		if len(values) > 0 {
+			nonEmpty[section] = values
+		}
+	}
+
+	return nonEmpty
+}
+
+func isSupportedFileType(outputType string) bool {
+	for _, fileType := range GetSupportedFileTypes() {
+		if fileType == outputType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package serializer
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestMigrate(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing migrating profiles from ini to json and back.")
+	testFactory, _, serializeErr := createTestIni(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+	_, secondSerializer, serializeErr := createTestIni(global.TEST_VAR_FIRST_PROFILE_LABEL, true)
+	assert.NoError(serializeErr)
+
+	migrateErr := MigrateWithOptions(testFactory, global.OUTPUT_TYPE_INI, global.OUTPUT_TYPE_JSON, MigrateOptions{Backup: true})
+	assert.NoError(migrateErr)
+	assert.Equal(global.OUTPUT_TYPE_JSON, testFactory.OutputType)
+	assert.FileExists(testFactory.CredentialFile + ".ini.bak")
+	assert.FileExists(secondSerializer.ConfigFile + ".ini.bak")
+
+	credentialContents, readErr := ioutil.ReadFile(testFactory.CredentialFile)
+	assert.NoError(readErr)
+	assert.True(strings.HasPrefix(string(credentialContents), "{"))
+
+	profiles, listErr := ListProfiles(testFactory)
+	assert.NoError(listErr)
+	assert.Equal([]string{global.DEFAULT_PROFILE_NAME, global.TEST_VAR_FIRST_PROFILE_LABEL}, profiles)
+
+	username, password, attributes, deserializeErr := New(testFactory, global.TEST_VAR_FIRST_PROFILE_LABEL).FromJson()
+	assert.NoError(deserializeErr)
+	assert.Equal(global.TEST_VAR_USERNAME_ALTERNATE, username)
+	assert.Equal(global.TEST_VAR_PASSWORD_ALTERNATE, password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+
+	migrateErr = Migrate(testFactory, global.OUTPUT_TYPE_JSON, global.OUTPUT_TYPE_INI)
+	assert.NoError(migrateErr)
+	assert.Equal(global.OUTPUT_TYPE_INI, testFactory.OutputType)
+	assert.NoFileExists(testFactory.CredentialFile + ".json.bak")
+
+	username, password, attributes, deserializeErr = New(testFactory, global.DEFAULT_PROFILE_NAME).FromIni()
+	assert.NoError(deserializeErr)
+	assert.Equal(global.TEST_VAR_USERNAME, username)
+	assert.Equal(global.TEST_VAR_PASSWORD, password)
+	assert.Equal(global.TEST_VAR_ATTRIBUTE_VALUE, attributes[global.TEST_VAR_FIRST_SECTION_KEY][global.TEST_VAR_ATTRIBUTE_NAME_LABEL])
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
+func TestMigrateInvalidOutputTypes(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing migrating profiles between unsupported output types.")
+	testFactory, _ := factory.New(global.TEST_VAR_APPLICATION_NAME)
+
+	migrateErr := Migrate(testFactory, global.OUTPUT_TYPE_INI, global.OUTPUT_TYPE_ENV)
+	assert.EqualError(migrateErr, ERR_MIGRATE_UNSUPPORTED_OUTPUT_TYPE)
+	migrateErr = Migrate(testFactory, global.OUTPUT_TYPE_INVALID, global.OUTPUT_TYPE_JSON)
+	assert.EqualError(migrateErr, ERR_MIGRATE_UNSUPPORTED_OUTPUT_TYPE)
+	migrateErr = Migrate(testFactory, global.OUTPUT_TYPE_JSON, global.OUTPUT_TYPE_JSON)
+	assert.EqualError(migrateErr, ERR_MIGRATE_SAME_OUTPUT_TYPE)
+	assert.Equal(global.OUTPUT_TYPE_INI, testFactory.OutputType)
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
+
+func TestRestoreMigrationFiles(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing the original files are restored when a migration fails.")
+	testFactory, testSerializer, serializeErr := createTestIni(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+	fileNames := []string{testFactory.CredentialFile, testSerializer.ConfigFile, testFactory.ConfigDirectory + global.TEST_VAR_FIRST_PROFILE_LABEL}
+
+	originals, readErr := readMigrationFiles(fileNames)
+	assert.NoError(readErr)
+	assert.Len(originals, 2)
+
+	migrateErr := writeMigratedProfiles(testFactory, global.OUTPUT_TYPE_JSON, []string{global.TEST_VAR_FIRST_PROFILE_LABEL}, map[string]migratedProfile{
+		global.TEST_VAR_FIRST_PROFILE_LABEL: {global.TEST_VAR_USERNAME, global.TEST_VAR_PASSWORD, nil},
+	}, originals)
+	assert.NoError(migrateErr)
+	assert.FileExists(fileNames[2])
+
+	restoreErr := restoreMigrationFiles(fileNames, originals)
+	assert.NoError(restoreErr)
+	assert.NoFileExists(fileNames[2])
+
+	username, password, _, deserializeErr := testSerializer.FromIni()
+	assert.NoError(deserializeErr)
+	assert.Equal(global.TEST_VAR_USERNAME, username)
+	assert.Equal(global.TEST_VAR_PASSWORD, password)
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
@@ -14,14 +14,16 @@ type Serializer struct {
 }
 
 type credentialSerializer struct {
-	Credentials map[string]serializedCredentials `json:"credentials",yaml:"credentials"`
+	FormatVersion string                           `json:"format_version,omitempty" yaml:"format_version,omitempty"`
+	Credentials   map[string]serializedCredentials `json:"credentials" yaml:"credentials"`
 }
 
 type serializedCredentials struct {
-	Username string `json:"username",yaml:"username"`
-	Password string `json:"password",yaml:"password"`
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
 }
 
 type profileSerializer struct {
-	Attributes map[string]map[string]string `json:"attributes",yaml:"attributes"`
+	FormatVersion string                       `json:"format_version,omitempty" yaml:"format_version,omitempty"`
+	Attributes    map[string]map[string]string `json:"attributes" yaml:"attributes"`
 }
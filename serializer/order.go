@@ -0,0 +1,31 @@
+package serializer
+
+import "sort"
+
+/*
+Go randomises the order maps are iterated in, so every serializer walks attributes through these helpers. This keeps
+the order of sections and keys in the output the same between saves, so files kept under version control only change
+when their values do.
+*/
+
+func sortedSectionNames(attributes map[string]map[string]string) []string {
+	sectionNames := make([]string, 0, len(attributes))
+
+	for sectionName := range attributes {
+		sectionNames = append(sectionNames, sectionName)
+	}
+
+	sort.Strings(sectionNames)
+	return sectionNames
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
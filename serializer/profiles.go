@@ -0,0 +1,147 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/engi-fyi/go-credentials/factory"
+	"github.com/engi-fyi/go-credentials/global"
+	"gopkg.in/ini.v1"
+)
+
+/*
+ListProfiles returns the name of every profile stored using the Factory's output type, sorted alphabetically. For the
+ini and json output types, this is every profile that has an entry in the credentials file or a file in the config
+directory. For the env output type, it is every profile that has a variable in the Factory's environment. For the aws
+output type, it is every profile in the AWS credentials file or config file.
+*/
+func ListProfiles(sourceFactory *factory.Factory) ([]string, error) {
+	return listProfilesAs(sourceFactory, sourceFactory.OutputType)
+}
+
+func listProfilesAs(sourceFactory *factory.Factory, outputType string) ([]string, error) {
+	sourceFactory.Log.Debug().Str("output_type", outputType).Msg("Listing profiles.")
+	profiles := make(map[string]bool)
+	var listErr error
+
+	switch outputType {
+	case global.OUTPUT_TYPE_INI:
+		listErr = listCredentialProfilesIni(sourceFactory.CredentialFile, profiles)
+	case global.OUTPUT_TYPE_JSON:
+		listErr = listCredentialProfilesJson(sourceFactory.CredentialFile, profiles)
+	case global.OUTPUT_TYPE_ENV:
+		listProfilesEnv(sourceFactory, profiles)
+	case global.OUTPUT_TYPE_AWS:
+		listErr = listProfilesAws(sourceFactory, profiles)
+	default:
+		sourceFactory.Log.Error().Str("unrecognized", outputType).Msg(ERR_UNRECOGNIZED_OUTPUT_TYPE)
+		return nil, errors.New(ERR_UNRECOGNIZED_OUTPUT_TYPE)
+	}
+
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	if outputType != global.OUTPUT_TYPE_ENV && outputType != global.OUTPUT_TYPE_AWS {
+		listErr = listConfigProfiles(sourceFactory.ConfigDirectory, profiles)
+
+		if listErr != nil {
+			return nil, listErr
+		}
+	}
+
+	profileNames := make([]string, 0, len(profiles))
+
+	for profileName := range profiles {
+		profileNames = append(profileNames, profileName)
+	}
+
+	sort.Strings(profileNames)
+	return profileNames, nil
+}
+
+func listCredentialProfilesIni(fileName string, profiles map[string]bool) error {
+	if _, statErr := os.Stat(fileName); os.IsNotExist(statErr) {
+		return nil
+	}
+
+	credentialIni, loadErr := ini.Load(fileName)
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	for _, sectionName := range credentialIni.SectionStrings() {
+		if sectionName != ini.DefaultSection && sectionName != global.FORMAT_SECTION_NAME {
+			profiles[sectionName] = true
+		}
+	}
+
+	return nil
+}
+
+func listCredentialProfilesJson(fileName string, profiles map[string]bool) error {
+	if _, statErr := os.Stat(fileName); os.IsNotExist(statErr) {
+		return nil
+	}
+
+	//#nosec
+	credentialContents, readErr := ioutil.ReadFile(fileName)
+
+	if readErr != nil {
+		return readErr
+	}
+
+	var existingCredential credentialSerializer
+	unmarshalErr := json.Unmarshal(credentialContents, &existingCredential)
+
+	if unmarshalErr != nil {
+		return unmarshalErr
+	}
+
+	for profileName := range existingCredential.Credentials {
+		profiles[profileName] = true
+	}
+
+	return nil
+}
+
+func listConfigProfiles(configDirectory string, profiles map[string]bool) error {
+	files, readErr := ioutil.ReadDir(configDirectory)
+
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return nil
+		}
+
+		return readErr
+	}
+
+	keyRegex := regexp.MustCompile(global.REGEX_KEY_NAME)
+
+	for _, file := range files {
+		if file.Mode().IsRegular() && keyRegex.MatchString(file.Name()) {
+			profiles[file.Name()] = true
+		}
+	}
+
+	return nil
+}
+
+func listProfilesEnv(sourceFactory *factory.Factory, profiles map[string]bool) {
+	prefix := sourceFactory.GetEnvironmentPrefix()
+	keySerializer := New(sourceFactory, global.DEFAULT_PROFILE_NAME)
+
+	for key := range sourceFactory.GetEnvironment() {
+		if strings.HasPrefix(strings.ToUpper(key), prefix) {
+			if profileName, _, _, didParse := keySerializer.ParseEnvironmentVariable(strings.ToUpper(key)); didParse {
+				profiles[profileName] = true
+			}
+		}
+	}
+}
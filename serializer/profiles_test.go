@@ -0,0 +1,37 @@
+package serializer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/engi-fyi/go-credentials/global"
+)
+
+func TestListProfiles(t *testing.T) {
+	assert, log := global.InitTest(t)
+	log.Info().Msg("Testing listing the profiles stored by a factory.")
+	testFactory, _, serializeErr := createTestIni(global.DEFAULT_PROFILE_NAME, false)
+	assert.NoError(serializeErr)
+	_, _, serializeErr = createTestIni(global.TEST_VAR_FIRST_PROFILE_LABEL, true)
+	assert.NoError(serializeErr)
+
+	profiles, listErr := ListProfiles(testFactory)
+	assert.NoError(listErr)
+	assert.Equal([]string{global.DEFAULT_PROFILE_NAME, global.TEST_VAR_FIRST_PROFILE_LABEL}, profiles)
+
+	testFactory.SetOutputType(global.OUTPUT_TYPE_ENV)
+	testFactory.SetEnvironment(map[string]string{
+		global.TEST_VAR_ENVIRONMENT_USERNAME_LABEL:   global.TEST_VAR_USERNAME,
+		"MTCA::SECOND_PROFILE::PASSWORD":             global.TEST_VAR_PASSWORD,
+		"OTHER_APPLICATION::THIRD_PROFILE::PASSWORD": global.TEST_VAR_PASSWORD,
+	})
+	profiles, listErr = ListProfiles(testFactory)
+	assert.NoError(listErr)
+	assert.Equal([]string{global.DEFAULT_PROFILE_NAME, global.TEST_VAR_SECOND_PROFILE_LABEL}, profiles)
+
+	testFactory.OutputType = global.OUTPUT_TYPE_INVALID
+	_, listErr = ListProfiles(testFactory)
+	assert.EqualError(listErr, ERR_UNRECOGNIZED_OUTPUT_TYPE)
+
+	os.RemoveAll(testFactory.ParentDirectory)
+}
@@ -0,0 +1,47 @@
+package serializer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+/*
+WriteFileAtomic writes contents to a temporary file in the same directory as fileName, then renames it over fileName, so
+that a reader or a crash part way through never sees a partly written file, and the old file is only replaced once the
+new one is complete. The temporary file is created with 0600 permissions, so the contents are never readable by others
+even for a moment, and is given perm before it is renamed, whatever the permissions of the file it replaces. Every
+credentials and config file the serializer saves or deletes a profile from is written this way.
+*/
+func WriteFileAtomic(fileName string, contents []byte, perm os.FileMode) error {
+	tempFile, tempErr := ioutil.TempFile(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+
+	if tempErr != nil {
+		return tempErr
+	}
+
+	tempName := tempFile.Name()
+	_, writeErr := tempFile.Write(contents)
+
+	if writeErr == nil {
+		writeErr = tempFile.Sync()
+	}
+
+	if closeErr := tempFile.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+
+	if writeErr == nil {
+		writeErr = os.Chmod(tempName, perm)
+	}
+
+	if writeErr == nil {
+		writeErr = os.Rename(tempName, fileName)
+	}
+
+	if writeErr != nil {
+		os.Remove(tempName)
+	}
+
+	return writeErr
+}